@@ -2,6 +2,8 @@ package imagor
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -20,6 +22,9 @@ type BlobType int
 
 const maxMemorySize = int64(100 << 20) // 100MB
 
+// DefaultMemoryThreshold default in-memory buffer threshold of streams with unknown size
+const DefaultMemoryThreshold = int64(4 << 20) // 4MB
+
 // BlobType enum
 const (
 	BlobTypeUnknown BlobType = iota
@@ -37,6 +42,10 @@ const (
 	BlobTypeBMP
 	BlobTypePDF
 	BlobTypeSVG
+	BlobTypeJXL
+	BlobTypeICO
+	BlobTypePSD
+	BlobTypeAPNG
 )
 
 // Blob imagor data blob abstraction
@@ -52,6 +61,10 @@ type Blob struct {
 	filepath      string
 	contentType   string
 	memory        *memory
+	mmap          *mmapFile
+
+	memoryBudget    *seekstream.MemoryBudget
+	memoryThreshold int64
 
 	Header http.Header
 	Stat   *Stat
@@ -108,6 +121,26 @@ func NewBlobFromFile(filepath string, checks ...func(os.FileInfo) error) *Blob {
 	return blob
 }
 
+// NewBlobFromFileMmap creates imagor Blob from file path and optional file info checks,
+// using memory-mapped file for zero-copy access. Falls back to file reads if mmap is unavailable
+func NewBlobFromFileMmap(filepath string, checks ...func(os.FileInfo) error) *Blob {
+	blob := NewBlobFromFile(filepath, checks...)
+	if blob.err != nil {
+		return blob
+	}
+	m := newMmapFile(filepath)
+	newFileReader := blob.newReader
+	blob.mmap = m
+	blob.fanout = false // mapped data is shared already
+	blob.newReader = func() (io.ReadCloser, int64, error) {
+		if r, size, err := newMmapReader(m); err == nil {
+			return r, size, nil
+		}
+		return newFileReader()
+	}
+	return blob
+}
+
 // NewBlobFromJsonMarshal creates imagor Blob from json marshal of any object
 func NewBlobFromJsonMarshal(v any) *Blob {
 	buf, err := json.Marshal(v)
@@ -163,6 +196,25 @@ var heic = []byte("heic")
 var mif1 = []byte("mif1")
 var msf1 = []byte("msf1")
 var avif = []byte("avif")
+var avis = []byte("avis")
+
+// heifBrands HEIF major or compatible brands
+var heifBrands = [][]byte{
+	heic, []byte("heix"), []byte("heim"), []byte("heis"),
+	[]byte("hevc"), []byte("hevx"), []byte("hevm"), []byte("hevs"),
+	mif1, msf1, []byte("mif2"),
+}
+
+// https://www.iso.org/standard/85066.html
+var jxlCodestream = []byte("\xFF\x0A")
+var jxlContainer = []byte("\x00\x00\x00\x0CJXL \x0D\x0A\x87\x0A")
+
+var icoHeader = []byte("\x00\x00\x01\x00")
+var psdHeader = []byte("8BPS")
+
+// https://wiki.mozilla.org/APNG_Specification
+var pngAcTL = []byte("acTL")
+var pngIDAT = []byte("IDAT")
 
 // Jp2 matches a JPEG 2000 Image file (ISO 15444-1).
 var jp2 = []byte{0x6a, 0x70, 0x32, 0x20}
@@ -306,17 +358,17 @@ func (b *Blob) doInit() {
 		if bytes.Equal(b.sniffBuf[:3], jpegHeader) {
 			b.blobType = BlobTypeJPEG
 		} else if bytes.Equal(b.sniffBuf[:4], pngHeader) {
-			b.blobType = BlobTypePNG
+			if isAPNG(b.sniffBuf) {
+				b.blobType = BlobTypeAPNG
+			} else {
+				b.blobType = BlobTypePNG
+			}
 		} else if bytes.Equal(b.sniffBuf[:3], gifHeader) {
 			b.blobType = BlobTypeGIF
 		} else if bytes.Equal(b.sniffBuf[8:12], webpHeader) {
 			b.blobType = BlobTypeWEBP
-		} else if bytes.Equal(b.sniffBuf[4:8], ftyp) && bytes.Equal(b.sniffBuf[8:12], avif) {
-			b.blobType = BlobTypeAVIF
-		} else if bytes.Equal(b.sniffBuf[4:8], ftyp) && (bytes.Equal(b.sniffBuf[8:12], heic) ||
-			bytes.Equal(b.sniffBuf[8:12], mif1) ||
-			bytes.Equal(b.sniffBuf[8:12], msf1)) {
-			b.blobType = BlobTypeHEIF
+		} else if bytes.Equal(b.sniffBuf[4:8], ftyp) {
+			b.blobType = sniffFtyp(b.sniffBuf)
 		} else if bytes.Equal(b.sniffBuf[:4], tifII) || bytes.Equal(b.sniffBuf[:4], tifMM) {
 			b.blobType = BlobTypeTIFF
 		} else if (bytes.Equal(b.sniffBuf[4:8], []byte{0x6A, 0x50, 0x20, 0x20}) ||
@@ -328,6 +380,13 @@ func (b *Blob) doInit() {
 			b.blobType = BlobTypePDF
 		} else if bytes.Equal(b.sniffBuf[:2], bmpHeader) {
 			b.blobType = BlobTypeBMP
+		} else if bytes.Equal(b.sniffBuf[:2], jxlCodestream) || bytes.Equal(b.sniffBuf[:12], jxlContainer) {
+			b.blobType = BlobTypeJXL
+		} else if bytes.Equal(b.sniffBuf[:4], icoHeader) && (b.sniffBuf[4] != 0 || b.sniffBuf[5] != 0) {
+			b.blobType = BlobTypeICO
+		} else if bytes.Equal(b.sniffBuf[:4], psdHeader) && b.sniffBuf[4] == 0 &&
+			(b.sniffBuf[5] == 1 || b.sniffBuf[5] == 2) {
+			b.blobType = BlobTypePSD
 		}
 	}
 	if b.contentType == "" {
@@ -356,6 +415,14 @@ func (b *Blob) doInit() {
 			b.contentType = "image/bmp"
 		case BlobTypeSVG:
 			b.contentType = "image/svg+xml"
+		case BlobTypeJXL:
+			b.contentType = "image/jxl"
+		case BlobTypeICO:
+			b.contentType = "image/x-icon"
+		case BlobTypePSD:
+			b.contentType = "image/vnd.adobe.photoshop"
+		case BlobTypeAPNG:
+			b.contentType = "image/apng"
 		default:
 			b.contentType = http.DetectContentType(b.sniffBuf)
 		}
@@ -388,7 +455,8 @@ func (b *Blob) IsEmpty() bool {
 	return b.blobType == BlobTypeEmpty
 }
 
-// SupportsAnimation check if blob supports animation
+// SupportsAnimation check if blob supports animation.
+// APNG is not included as libvips loads it as a static image of the first frame
 func (b *Blob) SupportsAnimation() bool {
 	b.init()
 	return b.blobType == BlobTypeGIF || b.blobType == BlobTypeWEBP
@@ -429,6 +497,25 @@ func (b *Blob) Memory() (data []byte, width, height, bands int, ok bool) {
 	return
 }
 
+// Mmap returns memory-mapped file data if Blob is created from file with mmap.
+// The mapping is kept alive until the end of the imagor request context,
+// not available outside of imagor request context
+func (b *Blob) Mmap(ctx context.Context) ([]byte, bool) {
+	if b.mmap == nil || ctx == nil {
+		return nil, false
+	}
+	r, ok := ctx.Value(imagorContextKey).(*imagorContextRef)
+	if !ok || r == nil {
+		return nil, false
+	}
+	data, release, err := b.mmap.acquire()
+	if err != nil {
+		return nil, false
+	}
+	r.Defer(release)
+	return data, true
+}
+
 // SetContentType set Blob content type. which overrides default sniffing if this is set
 func (b *Blob) SetContentType(contentType string) {
 	b.contentType = contentType
@@ -447,8 +534,20 @@ func (b *Blob) NewReader() (reader io.ReadCloser, size int64, err error) {
 }
 
 // NewReadSeeker create read seeker if reader supports seek,
-// or attempts to simulate seek using memory or temp file buffer
+// or attempts to simulate seek using memory or temp file buffer,
+// within the memory budget and threshold of the Imagor instance loading the blob
 func (b *Blob) NewReadSeeker() (io.ReadSeekCloser, int64, error) {
+	return b.NewReadSeekerWithBudget(b.memoryBudget, b.memoryThreshold)
+}
+
+// NewReadSeekerWithBudget create read seeker if reader supports seek,
+// or attempts to simulate seek using memory or temp file buffer.
+// Known size up to 100mb buffered in memory, unknown size up to threshold,
+// DefaultMemoryThreshold if 0. Spills to temp file beyond the threshold or memory budget.
+// Memory budget is not constrained if nil
+func (b *Blob) NewReadSeekerWithBudget(
+	budget *seekstream.MemoryBudget, threshold int64,
+) (io.ReadSeekCloser, int64, error) {
 	b.init()
 	if b.newReadSeeker != nil {
 		return b.newReadSeeker()
@@ -458,17 +557,13 @@ func (b *Blob) NewReadSeeker() (io.ReadSeekCloser, int64, error) {
 	if err != nil {
 		return nil, size, err
 	}
-	var buffer seekstream.Buffer
+	if threshold <= 0 {
+		threshold = DefaultMemoryThreshold
+	}
 	if size > 0 && size < maxMemorySize {
-		// in memory buffer if size is known and less then 100mb
-		buffer = seekstream.NewMemoryBuffer(size)
-	} else {
-		// otherwise temp file buffer
-		buffer, err = seekstream.NewTempFileBuffer("", "imagor-")
-		if err != nil {
-			return nil, size, err
-		}
+		threshold = max(threshold, size)
 	}
+	buffer := seekstream.NewHybridBuffer(size, threshold, budget, "", "imagor-")
 	return seekstream.New(reader, buffer), size, err
 }
 
@@ -547,6 +642,54 @@ func getExtension(typ BlobType) (ext string) {
 		ext = ".json"
 	case BlobTypeSVG:
 		ext = ".svg"
+	case BlobTypeJXL:
+		ext = ".jxl"
+	case BlobTypeICO:
+		ext = ".ico"
+	case BlobTypePSD:
+		ext = ".psd"
+	case BlobTypeAPNG:
+		ext = ".png"
 	}
 	return
 }
+
+// sniffFtyp determines HEIF or AVIF by ISOBMFF ftyp box major and compatible brands
+func sniffFtyp(buf []byte) BlobType {
+	var brands = [][]byte{buf[8:12]}
+	size := int(binary.BigEndian.Uint32(buf[:4]))
+	for i := 16; i+4 <= size && i+4 <= len(buf); i += 4 {
+		brands = append(brands, buf[i:i+4])
+	}
+	// avif takes precedence as mif1 is commonly the major brand of avif
+	for _, brand := range brands {
+		if bytes.Equal(brand, avif) || bytes.Equal(brand, avis) {
+			return BlobTypeAVIF
+		}
+	}
+	for _, brand := range brands {
+		for _, heif := range heifBrands {
+			if bytes.Equal(brand, heif) {
+				return BlobTypeHEIF
+			}
+		}
+	}
+	return BlobTypeUnknown
+}
+
+// isAPNG checks for acTL chunk before the first IDAT chunk of PNG
+func isAPNG(buf []byte) bool {
+	for i := 8; i+8 <= len(buf); {
+		length := int(binary.BigEndian.Uint32(buf[i : i+4]))
+		typ := buf[i+4 : i+8]
+		if bytes.Equal(typ, pngAcTL) {
+			return true
+		}
+		if bytes.Equal(typ, pngIDAT) || length < 0 {
+			return false
+		}
+		// chunk length, type, data and crc
+		i += 12 + length
+	}
+	return false
+}
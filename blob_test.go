@@ -2,12 +2,15 @@ package imagor
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"testing"
 
+	"github.com/kumparan/imagor/seekstream"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -67,6 +70,13 @@ func TestBlobTypes(t *testing.T) {
 			extension:   ".png",
 			bytesType:   BlobTypePNG,
 		},
+		{
+			name:        "apng",
+			path:        "animated.apng",
+			contentType: "image/apng",
+			extension:   ".png",
+			bytesType:   BlobTypeAPNG,
+		},
 		{
 			name:        "tiff",
 			path:        "gopher.tiff",
@@ -221,6 +231,183 @@ func TestBlobTypes(t *testing.T) {
 	}
 }
 
+func TestBlobReadSeekerMemoryThreshold(t *testing.T) {
+	buf := bytes.Repeat([]byte("imagor"), 1000)
+	readSeek := func(b *Blob, budget *seekstream.MemoryBudget, threshold int64) {
+		rs, _, err := b.NewReadSeekerWithBudget(budget, threshold)
+		require.NoError(t, err)
+		_, err = rs.Seek(100, io.SeekStart)
+		require.NoError(t, err)
+		buf2, err := io.ReadAll(rs)
+		require.NoError(t, err)
+		assert.Equal(t, buf[100:], buf2)
+		require.NoError(t, rs.Close())
+	}
+	newUnknownSizeBlob := func() *Blob {
+		return NewBlob(func() (io.ReadCloser, int64, error) {
+			return io.NopCloser(bytes.NewReader(buf)), 0, nil
+		})
+	}
+
+	budget := seekstream.NewMemoryBudget(0)
+	readSeek(newUnknownSizeBlob(), budget, 1024)
+	assert.Equal(t, int64(1), budget.Spills(), "unknown size beyond threshold spills")
+	assert.Zero(t, budget.Used())
+
+	budget = seekstream.NewMemoryBudget(0)
+	readSeek(newUnknownSizeBlob(), budget, 0)
+	assert.Zero(t, budget.Spills(), "within default threshold")
+
+	budget = seekstream.NewMemoryBudget(0)
+	readSeek(NewBlob(func() (io.ReadCloser, int64, error) {
+		return io.NopCloser(bytes.NewReader(buf)), int64(len(buf)), nil
+	}), budget, 1024)
+	assert.Zero(t, budget.Spills(), "known size within memory")
+
+	budget = seekstream.NewMemoryBudget(100)
+	readSeek(NewBlob(func() (io.ReadCloser, int64, error) {
+		return io.NopCloser(bytes.NewReader(buf)), int64(len(buf)), nil
+	}), budget, 0)
+	assert.Equal(t, int64(1), budget.Spills(), "known size beyond budget spills")
+}
+
+func TestBlobSniff(t *testing.T) {
+	pad := func(b []byte) []byte {
+		return append(b, make([]byte, 64)...)
+	}
+	ftypBox := func(major string, compatible ...string) []byte {
+		box := []byte("ftyp" + major + "\x00\x00\x00\x00")
+		for _, brand := range compatible {
+			box = append(box, brand...)
+		}
+		size := make([]byte, 4)
+		binary.BigEndian.PutUint32(size, uint32(len(box)+4))
+		return pad(append(size, box...))
+	}
+	pngChunk := func(typ string, length int) []byte {
+		chunk := make([]byte, 12+length)
+		binary.BigEndian.PutUint32(chunk, uint32(length))
+		copy(chunk[4:], typ)
+		return chunk
+	}
+	png := append([]byte("\x89PNG\r\n\x1a\n"), pngChunk("IHDR", 13)...)
+	tests := []struct {
+		name              string
+		buf               []byte
+		contentType       string
+		extension         string
+		bytesType         BlobType
+		supportsAnimation bool
+	}{
+		{
+			name:        "jxl codestream",
+			buf:         pad([]byte("\xFF\x0A\xFA\x7F")),
+			contentType: "image/jxl",
+			extension:   ".jxl",
+			bytesType:   BlobTypeJXL,
+		},
+		{
+			name:        "jxl container",
+			buf:         pad([]byte("\x00\x00\x00\x0CJXL \x0D\x0A\x87\x0A\x00\x00\x00\x14ftypjxl ")),
+			contentType: "image/jxl",
+			extension:   ".jxl",
+			bytesType:   BlobTypeJXL,
+		},
+		{
+			name:        "ico",
+			buf:         pad([]byte("\x00\x00\x01\x00\x01\x00\x10\x10\x00\x00\x01\x00\x20\x00")),
+			contentType: "image/x-icon",
+			extension:   ".ico",
+			bytesType:   BlobTypeICO,
+		},
+		{
+			name:        "psd",
+			buf:         pad([]byte("8BPS\x00\x01\x00\x00\x00\x00\x00\x00\x00\x03")),
+			contentType: "image/vnd.adobe.photoshop",
+			extension:   ".psd",
+			bytesType:   BlobTypePSD,
+		},
+		{
+			name:        "psb",
+			buf:         pad([]byte("8BPS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x03")),
+			contentType: "image/vnd.adobe.photoshop",
+			extension:   ".psd",
+			bytesType:   BlobTypePSD,
+		},
+		{
+			name:        "png",
+			buf:         pad(append(append(png, pngChunk("IDAT", 10)...), pngChunk("acTL", 8)...)),
+			contentType: "image/png",
+			extension:   ".png",
+			bytesType:   BlobTypePNG,
+		},
+		{
+			name:        "apng",
+			buf:         pad(append(append(png, pngChunk("acTL", 8)...), pngChunk("IDAT", 10)...)),
+			contentType: "image/apng",
+			extension:   ".png",
+			bytesType:   BlobTypeAPNG,
+		},
+		{
+			name:        "avif major brand",
+			buf:         ftypBox("avif", "mif1", "miaf"),
+			contentType: "image/avif",
+			extension:   ".avif",
+			bytesType:   BlobTypeAVIF,
+		},
+		{
+			name:        "avif compatible brand",
+			buf:         ftypBox("mif1", "mif1", "avif", "miaf"),
+			contentType: "image/avif",
+			extension:   ".avif",
+			bytesType:   BlobTypeAVIF,
+		},
+		{
+			name:        "avif sequence",
+			buf:         ftypBox("avis", "msf1", "avif"),
+			contentType: "image/avif",
+			extension:   ".avif",
+			bytesType:   BlobTypeAVIF,
+		},
+		{
+			name:        "heic",
+			buf:         ftypBox("heic", "mif1", "heic"),
+			contentType: "image/heif",
+			extension:   ".heif",
+			bytesType:   BlobTypeHEIF,
+		},
+		{
+			name:        "heix",
+			buf:         ftypBox("heix", "mif1", "heix"),
+			contentType: "image/heif",
+			extension:   ".heif",
+			bytesType:   BlobTypeHEIF,
+		},
+		{
+			name:        "heif compatible brand",
+			buf:         ftypBox("mif1", "mif1", "heic"),
+			contentType: "image/heif",
+			extension:   ".heif",
+			bytesType:   BlobTypeHEIF,
+		},
+		{
+			name:        "mp4 not image",
+			buf:         ftypBox("isom", "isom", "mp41"),
+			contentType: "video/mp4",
+			bytesType:   BlobTypeUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBlobFromBytes(tt.buf)
+			assert.Equal(t, tt.bytesType, b.BlobType())
+			assert.Equal(t, tt.contentType, b.ContentType())
+			assert.Equal(t, tt.extension, getExtension(b.BlobType()))
+			assert.Equal(t, tt.supportsAnimation, b.SupportsAnimation())
+		})
+	}
+}
+
 func TestNewEmptyBlob(t *testing.T) {
 	b := NewBlobFromBytes([]byte{})
 	assert.Empty(t, b.Sniff())
@@ -286,6 +473,60 @@ func TestNewBlobFromMemory(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestNewBlobFromFileMmap(t *testing.T) {
+	expected, err := os.ReadFile("testdata/demo1.jpg")
+	require.NoError(t, err)
+
+	b := NewBlobFromFileMmap("testdata/demo1.jpg")
+	assert.Equal(t, BlobTypeJPEG, b.BlobType())
+	assert.Equal(t, int64(len(expected)), b.Size())
+	buf, err := b.ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, expected, buf)
+
+	r, size, err := b.NewReadSeeker()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(expected)), size)
+	_, err = r.Seek(10, io.SeekStart)
+	require.NoError(t, err)
+	buf, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, expected[10:], buf)
+	require.NoError(t, r.Close())
+	assert.Zero(t, b.mmap.refs)
+
+	data, ok := b.Mmap(context.Background())
+	assert.False(t, ok, "requires imagor context")
+	assert.Empty(t, data)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = withContext(ctx)
+	data, ok = b.Mmap(ctx)
+	require.True(t, ok)
+	assert.Equal(t, expected, data)
+	assert.Equal(t, 1, b.mmap.refs)
+	mustContextRef(ctx).Done()
+	cancel()
+	assert.Zero(t, b.mmap.refs)
+	assert.Nil(t, b.mmap.data)
+
+	b = NewBlobFromFileMmap("testdata/not-exists.jpg")
+	assert.ErrorIs(t, b.Err(), ErrNotFound)
+	data, ok = b.Mmap(withContext(context.Background()))
+	assert.False(t, ok)
+	assert.Empty(t, data)
+
+	f, err := os.CreateTemp("", "tmpfile-")
+	require.NoError(t, err)
+	defer f.Close()
+	defer os.Remove(f.Name())
+	b = NewBlobFromFileMmap(f.Name())
+	assert.Equal(t, BlobTypeEmpty, b.BlobType())
+	buf, err = b.ReadAll()
+	assert.NoError(t, err)
+	assert.Empty(t, buf)
+}
+
 func TestNewJsonMarshalBlob(t *testing.T) {
 	b := NewBlobFromJsonMarshal(map[string]string{
 		"foo": "bar",
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/kumparan/imagor/imagorpath"
+)
+
+// Builder fluent imagor endpoint builder
+type Builder struct {
+	client *Client
+	params imagorpath.Params
+}
+
+// NewBuilder create Builder for image without Client
+func NewBuilder(image string) *Builder {
+	return &Builder{params: imagorpath.Params{Image: image}}
+}
+
+// FromParams create Builder from existing Params
+func FromParams(p imagorpath.Params) *Builder {
+	b := &Builder{params: p}
+	b.params.Filters = append(imagorpath.Filters(nil), p.Filters...)
+	b.params.Path = ""
+	b.params.Hash = ""
+	b.params.Unsafe = false
+	return b
+}
+
+// Params returns a copy of the built Params
+func (b *Builder) Params() imagorpath.Params {
+	p := b.params
+	p.Filters = append(imagorpath.Filters(nil), b.params.Filters...)
+	return p
+}
+
+// Path returns the imagor endpoint path without signature
+func (b *Builder) Path() string {
+	return imagorpath.GeneratePath(b.params)
+}
+
+// Unsafe returns the unsafe imagor endpoint
+func (b *Builder) Unsafe() string {
+	return imagorpath.GenerateUnsafe(b.params)
+}
+
+// Sign returns the imagor endpoint signed with signer
+func (b *Builder) Sign(signer imagorpath.Signer) string {
+	return imagorpath.Generate(b.params, signer)
+}
+
+// URL returns the imagor endpoint URL using the client base URL and signer
+func (b *Builder) URL() string {
+	var baseURL string
+	var signer imagorpath.Signer
+	if b.client != nil {
+		baseURL = b.client.BaseURL
+		signer = b.client.Signer
+	}
+	return baseURL + "/" + imagorpath.Generate(b.params, signer)
+}
+
+// JSON returns the JSON params request body signed with the client signer,
+// as an alternative to the URL path for long endpoints
+func (b *Builder) JSON() []byte {
+	var signer imagorpath.Signer
+	if b.client != nil {
+		signer = b.client.Signer
+	}
+	return imagorpath.GenerateJSON(b.params, signer)
+}
+
+// String implements fmt.Stringer, returns the endpoint URL
+func (b *Builder) String() string {
+	return b.URL()
+}
+
+// Meta returns image metadata JSON instead of the image
+func (b *Builder) Meta() *Builder {
+	b.params.Meta = true
+	return b
+}
+
+// Trim removes surrounding space based on top-left pixel color
+func (b *Builder) Trim() *Builder {
+	b.params.Trim = true
+	return b
+}
+
+// TrimBy removes surrounding space based on the pixel color at position,
+// imagorpath.TrimByTopLeft or imagorpath.TrimByBottomRight, with color tolerance
+func (b *Builder) TrimBy(position string, tolerance int) *Builder {
+	b.params.Trim = true
+	b.params.TrimBy = position
+	b.params.TrimTolerance = tolerance
+	return b
+}
+
+// Crop manual crop by coordinates of top-left and bottom-right points,
+// in pixels or ratios of the image dimensions between 0.0 and 1.0
+func (b *Builder) Crop(left, top, right, bottom float64) *Builder {
+	b.params.CropLeft = left
+	b.params.CropTop = top
+	b.params.CropRight = right
+	b.params.CropBottom = bottom
+	return b
+}
+
+// FitIn fits the image within the target dimensions instead of cropping
+func (b *Builder) FitIn() *Builder {
+	b.params.FitIn = true
+	return b
+}
+
+// Stretch resizes the image to the target dimensions without keeping aspect ratio
+func (b *Builder) Stretch() *Builder {
+	b.params.Stretch = true
+	return b
+}
+
+// Resize target width and height. 0 means proportional to the other dimension
+func (b *Builder) Resize(width, height int) *Builder {
+	b.params.Width = width
+	b.params.Height = height
+	return b
+}
+
+// HFlip flips the image horizontally
+func (b *Builder) HFlip() *Builder {
+	b.params.HFlip = true
+	return b
+}
+
+// VFlip flips the image vertically
+func (b *Builder) VFlip() *Builder {
+	b.params.VFlip = true
+	return b
+}
+
+// Padding adds padding in pixels around the resized image
+func (b *Builder) Padding(left, top, right, bottom int) *Builder {
+	b.params.PaddingLeft = left
+	b.params.PaddingTop = top
+	b.params.PaddingRight = right
+	b.params.PaddingBottom = bottom
+	return b
+}
+
+// HAlign horizontal crop alignment, imagorpath.HAlignLeft or imagorpath.HAlignRight
+func (b *Builder) HAlign(align string) *Builder {
+	b.params.HAlign = align
+	return b
+}
+
+// VAlign vertical crop alignment, imagorpath.VAlignTop or imagorpath.VAlignBottom
+func (b *Builder) VAlign(align string) *Builder {
+	b.params.VAlign = align
+	return b
+}
+
+// Smart uses smart detection of the focal point for cropping
+func (b *Builder) Smart() *Builder {
+	b.params.Smart = true
+	return b
+}
+
+// Filter appends a filter by name with raw arguments.
+// Arguments are not escaped, use typed filter methods where available
+func (b *Builder) Filter(name string, args ...string) *Builder {
+	b.params.Filters = append(b.params.Filters, imagorpath.Filter{
+		Name: name, Args: strings.Join(args, ","),
+	})
+	return b
+}
+
+// Position filter position argument,
+// either an alignment keyword, pixel offset or percentage
+type Position string
+
+const (
+	// Left left alignment position
+	Left Position = imagorpath.HAlignLeft
+	// Right right alignment position
+	Right Position = imagorpath.HAlignRight
+	// Top top alignment position
+	Top Position = imagorpath.VAlignTop
+	// Bottom bottom alignment position
+	Bottom Position = imagorpath.VAlignBottom
+	// Center center alignment position
+	Center Position = "center"
+	// Repeat repeat position, watermark only
+	Repeat Position = "repeat"
+)
+
+// Px pixel offset position. Negative offset counts from the right or bottom
+func Px(n int) Position {
+	return Position(strconv.Itoa(n))
+}
+
+// Percent percentage offset position of the image dimension
+func Percent(n int) Position {
+	return Position(strconv.Itoa(n) + "p")
+}
+
+// escape escapes nested image path or text argument,
+// unescaped by the processor on filter execution
+func escape(s string) string {
+	return url.QueryEscape(s)
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/kumparan/imagor/imagorpath"
+)
+
+// Client imagor URL client, building signed or unsafe imagor endpoints
+type Client struct {
+	// BaseURL imagor server base URL prepended to generated endpoints
+	BaseURL string
+	// Signer URL signature signer. Unsafe endpoints generated if nil
+	Signer imagorpath.Signer
+	// CardPathPrefix social card endpoint path prefix, /card by default
+	CardPathPrefix string
+}
+
+// New create Client
+func New(options ...Option) *Client {
+	c := &Client{CardPathPrefix: "/card/"}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Image create Builder for image with the client base URL and signer
+func (c *Client) Image(image string) *Builder {
+	b := NewBuilder(image)
+	b.client = c
+	return b
+}
+
+// Card returns the social card endpoint URL of template name with slot values,
+// using the client base URL and signer
+func (c *Client) Card(name string, values url.Values) string {
+	return c.BaseURL + c.CardPathPrefix + imagorpath.GenerateCardPath(name, values, c.Signer)
+}
+
+// Option Client option
+type Option func(c *Client)
+
+// WithBaseURL with imagor server base URL option
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithSigner with URL signature signer option
+func WithSigner(signer imagorpath.Signer) Option {
+	return func(c *Client) {
+		c.Signer = signer
+	}
+}
+
+// WithCardPathPrefix with social card endpoint path prefix option
+func WithCardPathPrefix(pathPrefix string) Option {
+	return func(c *Client) {
+		if pathPrefix = strings.Trim(pathPrefix, "/"); pathPrefix != "" {
+			c.CardPathPrefix = "/" + pathPrefix + "/"
+		}
+	}
+}
+
+// WithSecret with URL signature secret option, using default SHA1 signer
+func WithSecret(secret string) Option {
+	return func(c *Client) {
+		if secret != "" {
+			c.Signer = imagorpath.NewDefaultSigner(secret)
+		}
+	}
+}
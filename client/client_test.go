@@ -0,0 +1,218 @@
+package client
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kumparan/imagor/imagorpath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *Builder
+		path    string
+	}{
+		{
+			name:    "image only",
+			builder: NewBuilder("gopher.png"),
+			path:    "gopher.png",
+		},
+		{
+			name: "resize and filters",
+			builder: NewBuilder("raw.githubusercontent.com/cshum/imagor/master/testdata/gopher.png").
+				FitIn().Resize(500, 400).Padding(20, 10, 20, 10).
+				Fill("#fff").Format("webp").Quality(80),
+			path: "fit-in/500x400/20x10/filters:fill(fff):format(webp):quality(80)/raw.githubusercontent.com/cshum/imagor/master/testdata/gopher.png",
+		},
+		{
+			name: "encoder options",
+			builder: NewBuilder("gopher.png").Format("jpeg").
+				Progressive(false).Subsampling("444").Trellis().Effort(4).
+				Lossless().NearLossless().Dither(0.5),
+			path: "filters:format(jpeg):progressive(false):subsampling(444):trellis():effort(4):lossless():near_lossless():dither(0.5)/gopher.png",
+		},
+		{
+			name: "fill blur",
+			builder: NewBuilder("gopher.png").FitIn().Resize(320, 180).
+				FillBlur(0, 0).FillBlur(20, 0).FillBlur(0, -30),
+			path: "fit-in/320x180/filters:fill(blur):fill(blur,20):fill(blur,50,-30)/gopher.png",
+		},
+		{
+			name: "transforms",
+			builder: NewBuilder("gopher.png").RotateAngle(3.5, "").RotateAngle(-15, "#ffcc00").
+				Affine(1, 0.3, 0, 1, "none").Perspective(0.1, 0, 0.9, 0.1, 1, 1, 0, 0.9, ""),
+			path: "filters:rotate(3.5):rotate(-15,ffcc00):affine(1,0.3,0,1,none):perspective(0.1,0,0.9,0.1,1,1,0,0.9)/gopher.png",
+		},
+		{
+			name: "redact",
+			builder: NewBuilder("gopher.png").Resize(300, 200).
+				Redact(10, 20, 110, 120, "").Redact(0.5, 0.5, 0.8, 0.6, "blur").Redact(1, 2, 3, 4, "#000").Pixelate(8),
+			path: "300x200/filters:redact(10,20,110,120):redact(0.5,0.5,0.8,0.6,blur):redact(1,2,3,4,000):pixelate(8)/gopher.png",
+		},
+		{
+			name: "layers",
+			builder: NewBuilder("gopher.png").
+				Layer("logo.png", Right, Bottom, 20, "multiply").
+				LayerSize("badge.png", Percent(10), Center, 0, "", Percent(30), "", -15),
+			path: "filters:layer(logo.png,right,bottom,20,multiply):layer(badge.png,10p,center,0,over,30p,none,-15)/gopher.png",
+		},
+		{
+			name: "target quality",
+			builder: NewBuilder("gopher.png").Format("webp").
+				Quality(90).TargetQuality(0.95).MaxBytes(20000),
+			path: "filters:format(webp):quality(90):target_quality(0.95):max_bytes(20000)/gopher.png",
+		},
+		{
+			name: "crop flip align smart",
+			builder: NewBuilder("gopher.png").
+				Meta().TrimBy(imagorpath.TrimByBottomRight, 10).Crop(0.1, 0.2, 0.9, 0.8).
+				Resize(-300, 200).VFlip().HAlign(imagorpath.HAlignLeft).VAlign(imagorpath.VAlignTop).Smart(),
+			path: "meta/trim:bottom-right:10/0.1x0.2:0.9x0.8/-300x-200/left/top/smart/gopher.png",
+		},
+		{
+			name: "focal",
+			builder: NewBuilder("gopher.png").Resize(300, 200).
+				Focal(10, 20, 110, 120).FocalPoint(0.3, 0.5),
+			path: "300x200/filters:focal(10x20:110x120):focal(0.3x0.5)/gopher.png",
+		},
+		{
+			name: "nested watermark image",
+			builder: NewBuilder("gopher.png").
+				Watermark(
+					NewBuilder("https://example.com/logo.png?v=1").FitIn().Resize(100, 100).Fill("none").Path(),
+					Right, Px(-10), 25,
+				).
+				WatermarkRatio("logo.png", Repeat, Percent(20), 0, 30, 0),
+			path: "filters:watermark(fit-in%2F100x100%2Ffilters%3Afill%28none%29%2Fhttps%253A%252F%252Fexample.com%252Flogo.png%253Fv%253D1,right,-10,25):watermark(logo.png,repeat,20p,0,30,none)/gopher.png",
+		},
+		{
+			name: "label text",
+			builder: NewBuilder("gopher.png").
+				Label("Hello, World (1)", Center, Bottom, 30, "#ff0000", 10).
+				LabelFont("Hi", Px(10), Px(10), 20, "white", 0, "sans bold"),
+			path: "filters:label(Hello%2C+World+%281%29,center,bottom,30,ff0000,10):label(Hi,10,10,20,white,0,sans+bold)/gopher.png",
+		},
+		{
+			name: "text",
+			builder: NewBuilder("gopher.png").
+				Text("Hello, World", Center, Bottom, 32, "white", 0, "sans bold",
+					"width:80p", "align:center", "stroke:2:000000", "shadow:2:2:3", "fontfile:fonts/Inter.ttf"),
+			path: "filters:text(Hello%2C+World,center,bottom,32,white,0,sans+bold,width:80p,align:center,stroke:2:000000,shadow:2:2:3,fontfile:fonts%2FInter.ttf)/gopher.png",
+		},
+		{
+			name: "card effects",
+			builder: NewBuilder("gopher.png").
+				FitIn().Resize(300, 200).Vignette(30).Border(4, "#ffffff").Shadow(4, 6, 8, "black", 40),
+			path: "fit-in/300x200/filters:vignette(30):border(4,ffffff):shadow(4,6,8,black,40)/gopher.png",
+		},
+		{
+			name: "mask",
+			builder: NewBuilder("gopher.png").
+				Mask("circle", false).Mask("masks/badge.png", true).Mask("path:M50 0 L100 100 L0 100 Z", false),
+			path: "filters:mask(circle):mask(masks%2Fbadge.png,invert):mask(path%3AM50+0+L100+100+L0+100+Z)/gopher.png",
+		},
+		{
+			name: "colour adjustments",
+			builder: NewBuilder("gopher.png").
+				Gamma(1.2).Levels(10, 245, 1).Levels(0, 255, 0.8).
+				Curves([2]float64{0, 0}, [2]float64{64, 50}, [2]float64{255, 255}).Normalize(0.5).Equalize(),
+			path: "filters:gamma(1.2):levels(10,245):levels(0,255,0.8):curves(0:0,64:50,255:255):normalize(0.5):equalize()/gopher.png",
+		},
+		{
+			name: "tints",
+			builder: NewBuilder("gopher.png").
+				Sepia(80).Duotone("#1a1a40", "ffd166").Colorize("red", 30).Gradient(90, "none", "#000000", 70),
+			path: "filters:sepia(80):duotone(1a1a40,ffd166):colorize(red,30):gradient(90,none,000000,70)/gopher.png",
+		},
+		{
+			name: "transparent",
+			builder: NewBuilder("gopher.png").
+				Transparent("#ffffff", 10, 5, false).Transparent("00ff00", 20, 0, true),
+			path: "filters:transparent(ffffff,10,5):transparent(00ff00,20,0,flood)/gopher.png",
+		},
+		{
+			name: "resample convolution",
+			builder: NewBuilder("gopher.png").
+				Resample("nearest").Convolution([]float64{1, 2, 1, 2, 4, 2, 1, 2, 1}, 3, true),
+			path: "filters:resample(nearest):convolution(1;2;1;2;4;2;1;2;1,3,true)/gopher.png",
+		},
+		{
+			name: "escaped image",
+			builder: NewBuilder("https://example.com/image.jpg?width=100").
+				Resize(100, 0).Blur(2.5).RoundCorner(10, 20, "").Modulate(100, 50, 0),
+			path: "100x0/filters:blur(2.5):round_corner(10,20):modulate(100,50,0)/https%3A%2F%2Fexample.com%2Fimage.jpg%3Fwidth%3D100",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.path, tt.builder.Path())
+			assert.Equal(t, "unsafe/"+tt.path, tt.builder.Unsafe())
+			// round trip
+			p := imagorpath.Parse(tt.builder.Unsafe())
+			assert.Equal(t, tt.path, p.Path)
+			assert.True(t, p.Unsafe)
+			assert.Equal(t, tt.path, imagorpath.GeneratePath(p))
+			assert.Equal(t, tt.builder.Params().Image, p.Image)
+			assert.Equal(t, tt.builder.Params().Filters, p.Filters)
+			assert.Equal(t, tt.path, FromParams(p).Path())
+		})
+	}
+}
+
+func TestBuilderNestedWatermarkArgs(t *testing.T) {
+	nested := NewBuilder("logo.png").Resize(100, 0).Filter("fill", "none").Path()
+	p := imagorpath.Parse(NewBuilder("gopher.png").Watermark(nested, Center, Center, 0).Unsafe())
+	assert.Len(t, p.Filters, 1)
+	args := strings.Split(p.Filters[0].Args, ",")
+	assert.Len(t, args, 4)
+	image, err := url.QueryUnescape(args[0])
+	assert.NoError(t, err)
+	assert.Equal(t, nested, image)
+}
+
+func TestClient(t *testing.T) {
+	signer := imagorpath.NewDefaultSigner("mysecret")
+	c := New(WithBaseURL("https://imagor.example.com/"), WithSigner(signer))
+	b := c.Image("gopher.png").FitIn().Resize(500, 400).Fill("white")
+	path := "fit-in/500x400/filters:fill(white)/gopher.png"
+	assert.Equal(t, "https://imagor.example.com/"+signer.Sign(path)+"/"+path, b.URL())
+	assert.Equal(t, b.URL(), b.String())
+	assert.Equal(t, b.Sign(signer), imagorpath.Generate(b.Params(), signer))
+
+	p := imagorpath.Parse(b.Sign(signer))
+	assert.Equal(t, signer.Sign(path), p.Hash)
+	assert.Equal(t, path, p.Path)
+
+	assert.Equal(t, "/unsafe/gopher.png", New().Image("gopher.png").URL())
+	assert.Equal(t, "/unsafe/gopher.png", New(WithSecret("")).Image("gopher.png").URL())
+	assert.NotNil(t, New(WithSecret("abc")).Signer)
+
+	body, err := imagorpath.ParseJSON(bytes.NewReader(b.JSON()))
+	assert.NoError(t, err)
+	assert.Equal(t, imagorpath.SignJSON(b.Params(), signer), body.Hash)
+	assert.True(t, strings.Contains(string(NewBuilder("gopher.png").JSON()), `"unsafe":true`))
+
+	// Params returns a copy
+	params := b.Params()
+	params.Filters[0].Args = "black"
+	assert.Equal(t, "white", b.Params().Filters[0].Args)
+}
+
+func TestCard(t *testing.T) {
+	signer := imagorpath.NewDefaultSigner("mysecret")
+	values := url.Values{"title": {"Hello, World"}}
+	c := New(WithBaseURL("https://imagor.example.com/"), WithSigner(signer))
+	assert.Equal(t, "https://imagor.example.com/card/"+signer.Sign("article?title=Hello%2C+World")+"/article?title=Hello%2C+World",
+		c.Card("article", values))
+	assert.Equal(t, "/og/unsafe/article?title=Hello%2C+World", New(WithCardPathPrefix("/og/")).Card("article", values))
+}
+
+func TestExpire(t *testing.T) {
+	ts := time.UnixMilli(1700000000000)
+	assert.Equal(t, "filters:expire(1700000000000)/gopher.png", NewBuilder("gopher.png").Expire(ts).Path())
+}
@@ -0,0 +1,513 @@
+package client
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Attachment returns the image as attachment with optional filename
+func (b *Builder) Attachment(filename string) *Builder {
+	if filename == "" {
+		return b.Filter("attachment")
+	}
+	return b.Filter("attachment", escape(filename))
+}
+
+// Expire expires the endpoint after time t
+func (b *Builder) Expire(t time.Time) *Builder {
+	return b.Filter("expire", strconv.FormatInt(t.UnixMilli(), 10))
+}
+
+// Preview skips result storage
+func (b *Builder) Preview() *Builder {
+	return b.Filter("preview")
+}
+
+// Raw returns the source image without processing
+func (b *Builder) Raw() *Builder {
+	return b.Filter("raw")
+}
+
+// Format output image format, e.g. jpeg, png, webp, gif, avif, jxl
+func (b *Builder) Format(format string) *Builder {
+	return b.Filter("format", format)
+}
+
+// Quality output image quality between 0 and 100
+func (b *Builder) Quality(quality int) *Builder {
+	return b.Filter("quality", strconv.Itoa(quality))
+}
+
+// AutoJPG converts the output image to JPEG
+func (b *Builder) AutoJPG() *Builder {
+	return b.Filter("autojpg")
+}
+
+// MaxBytes reduces quality until the output image fits within n bytes
+func (b *Builder) MaxBytes(n int) *Builder {
+	return b.Filter("max_bytes", strconv.Itoa(n))
+}
+
+// TargetQuality searches the lowest quality of which the output image
+// reaches the SSIM similarity between 0.0 and 1.0
+func (b *Builder) TargetQuality(ssim float64) *Builder {
+	return b.Filter("target_quality", ftoa(ssim))
+}
+
+// Palette enables palette quantisation for PNG output
+func (b *Builder) Palette() *Builder {
+	return b.Filter("palette")
+}
+
+// BitDepth PNG output bit depth between 1 and 8
+func (b *Builder) BitDepth(depth int) *Builder {
+	return b.Filter("bitdepth", strconv.Itoa(depth))
+}
+
+// Compression PNG output compression level between 0 and 9
+func (b *Builder) Compression(level int) *Builder {
+	return b.Filter("compression", strconv.Itoa(level))
+}
+
+// Lossless enables lossless encoding for WebP, AVIF, HEIF, JPEG 2000 and JPEG XL output
+func (b *Builder) Lossless() *Builder {
+	return b.Filter("lossless")
+}
+
+// NearLossless enables near lossless encoding for WebP output
+func (b *Builder) NearLossless() *Builder {
+	return b.Filter("near_lossless")
+}
+
+// Effort encoder CPU effort, higher is slower with smaller output.
+// WebP 0 to 6, AVIF 0 to 9, JPEG XL 1 to 9, GIF 1 to 10
+func (b *Builder) Effort(level int) *Builder {
+	return b.Filter("effort", strconv.Itoa(level))
+}
+
+// Subsampling chroma subsampling mode for JPEG and AVIF output, 444, 420 or auto
+func (b *Builder) Subsampling(mode string) *Builder {
+	return b.Filter("subsampling", mode)
+}
+
+// Progressive enables or disables progressive JPEG or interlaced PNG output
+func (b *Builder) Progressive(enabled bool) *Builder {
+	return b.Filter("progressive", strconv.FormatBool(enabled))
+}
+
+// Trellis enables trellis quantisation for JPEG output
+func (b *Builder) Trellis() *Builder {
+	return b.Filter("trellis")
+}
+
+// Dither amount of dithering between 0 and 1 for PNG palette and GIF output
+func (b *Builder) Dither(amount float64) *Builder {
+	return b.Filter("dither", ftoa(amount))
+}
+
+// StripExif removes Exif metadata
+func (b *Builder) StripExif() *Builder {
+	return b.Filter("strip_exif")
+}
+
+// StripICC removes ICC profile
+func (b *Builder) StripICC() *Builder {
+	return b.Filter("strip_icc")
+}
+
+// StripMetadata removes all metadata
+func (b *Builder) StripMetadata() *Builder {
+	return b.Filter("strip_metadata")
+}
+
+// MaxFrames limits the number of animation frames loaded
+func (b *Builder) MaxFrames(n int) *Builder {
+	return b.Filter("max_frames", strconv.Itoa(n))
+}
+
+// Page loads page n of a multi-page image, starting from 1
+func (b *Builder) Page(n int) *Builder {
+	return b.Filter("page", strconv.Itoa(n))
+}
+
+// DPI loads vector images with dpi
+func (b *Builder) DPI(dpi int) *Builder {
+	return b.Filter("dpi", strconv.Itoa(dpi))
+}
+
+// Orient rotates the image by angle before resize, multiples of 90
+func (b *Builder) Orient(angle int) *Builder {
+	return b.Filter("orient", strconv.Itoa(angle))
+}
+
+// Upscale allows fit-in to upscale the image
+func (b *Builder) Upscale() *Builder {
+	return b.Filter("upscale")
+}
+
+// Resample sets resampling kernel of the resize, one of
+// nearest, linear, cubic, mitchell, lanczos2 or lanczos3
+func (b *Builder) Resample(kernel string) *Builder {
+	return b.Filter("resample", kernel)
+}
+
+// NoUpscale prevents the image from being upscaled
+func (b *Builder) NoUpscale() *Builder {
+	return b.Filter("no_upscale")
+}
+
+// Focal focal region for cropping by top-left and bottom-right points,
+// in pixels or ratios of the image dimensions between 0.0 and 1.0
+func (b *Builder) Focal(left, top, right, bottom float64) *Builder {
+	return b.Filter("focal", ftoa(left)+"x"+ftoa(top)+":"+ftoa(right)+"x"+ftoa(bottom))
+}
+
+// FocalPoint focal point for cropping,
+// in pixels or ratios of the image dimensions between 0.0 and 1.0
+func (b *Builder) FocalPoint(x, y float64) *Builder {
+	return b.Filter("focal", ftoa(x)+"x"+ftoa(y))
+}
+
+// Proportion scales the image by percentage
+func (b *Builder) Proportion(percentage float64) *Builder {
+	return b.Filter("proportion", ftoa(percentage))
+}
+
+// TrimFilter trim filter applied after resize, with color tolerance and
+// optional position, imagorpath.TrimByTopLeft or imagorpath.TrimByBottomRight
+func (b *Builder) TrimFilter(tolerance int, position string) *Builder {
+	if position != "" {
+		return b.Filter("trim", strconv.Itoa(tolerance), position)
+	}
+	return b.Filter("trim", strconv.Itoa(tolerance))
+}
+
+// Fill fills the fit-in and padding area with color,
+// either a color name, hex, "auto" or "none" for transparent
+func (b *Builder) Fill(color string) *Builder {
+	return b.Filter("fill", colour(color))
+}
+
+// FillBlur fills the fit-in and padding area with blurred copy of the image scaled up to cover,
+// with blur sigma and brightness -100 to 100 in %. Default sigma is used if 0
+func (b *Builder) FillBlur(sigma float64, brightness int) *Builder {
+	if sigma <= 0 && brightness == 0 {
+		return b.Filter("fill", "blur")
+	}
+	if sigma <= 0 {
+		sigma = 50
+	}
+	if brightness == 0 {
+		return b.Filter("fill", "blur", ftoa(sigma))
+	}
+	return b.Filter("fill", "blur", ftoa(sigma), strconv.Itoa(brightness))
+}
+
+// PaddingFilter padding filter applied after resize with fill color
+func (b *Builder) PaddingFilter(color string, left, top, right, bottom int) *Builder {
+	return b.Filter("padding", colour(color),
+		strconv.Itoa(left), strconv.Itoa(top), strconv.Itoa(right), strconv.Itoa(bottom))
+}
+
+// Rotate rotates the image counterclockwise by angle, multiples of 90
+func (b *Builder) Rotate(angle int) *Builder {
+	return b.Filter("rotate", strconv.Itoa(angle))
+}
+
+// Pixelate pixelates the image by blocks of size in pixels
+func (b *Builder) Pixelate(size int) *Builder {
+	return b.Filter("pixelate", strconv.Itoa(size))
+}
+
+// Redact redacts region by coordinates of top-left and bottom-right points of the original image,
+// in pixels or ratios of the image dimensions between 0.0 and 1.0.
+// Mode is "pixelate" by default if empty, "blur" or fill color
+func (b *Builder) Redact(left, top, right, bottom float64, mode string) *Builder {
+	args := []string{ftoa(left), ftoa(top), ftoa(right), ftoa(bottom)}
+	if mode != "" {
+		args = append(args, colour(mode))
+	}
+	return b.Filter("redact", args...)
+}
+
+// RotateAngle rotates the image counterclockwise by arbitrary angle in degrees,
+// with background color, "none" for transparent, or "crop" to the largest inscribed rectangle.
+// Background is transparent for image with alpha or black otherwise if empty
+func (b *Builder) RotateAngle(angle float64, background string) *Builder {
+	if background == "" {
+		return b.Filter("rotate", ftoa(angle))
+	}
+	return b.Filter("rotate", ftoa(angle), colour(background))
+}
+
+// Affine transforms the image by matrix [a, b, c, d] with background color
+func (b *Builder) Affine(a, b2, c, d float64, background string) *Builder {
+	args := []string{ftoa(a), ftoa(b2), ftoa(c), ftoa(d)}
+	if background != "" {
+		args = append(args, colour(background))
+	}
+	return b.Filter("affine", args...)
+}
+
+// Perspective maps the quadrilateral of top-left, top-right, bottom-right and bottom-left points
+// to rectangle with background color. Points are in pixels or ratios of the image dimensions
+func (b *Builder) Perspective(x1, y1, x2, y2, x3, y3, x4, y4 float64, background string) *Builder {
+	args := []string{ftoa(x1), ftoa(y1), ftoa(x2), ftoa(y2), ftoa(x3), ftoa(y3), ftoa(x4), ftoa(y4)}
+	if background != "" {
+		args = append(args, colour(background))
+	}
+	return b.Filter("perspective", args...)
+}
+
+// SetFrames sets the number of animation frames with frame delay in milliseconds.
+// Delay is not set if 0
+func (b *Builder) SetFrames(n int, delay int) *Builder {
+	if delay > 0 {
+		return b.Filter("set_frames", strconv.Itoa(n), strconv.Itoa(delay))
+	}
+	return b.Filter("set_frames", strconv.Itoa(n))
+}
+
+// Watermark composites the image path at position x, y with alpha transparency between 0 and 100.
+// The image path may be another imagor endpoint path, and is escaped accordingly
+func (b *Builder) Watermark(image string, x, y Position, alpha float64) *Builder {
+	return b.Filter("watermark", escape(image), string(x), string(y), ftoa(alpha))
+}
+
+// WatermarkRatio Watermark with the watermark image resized to percentages of the image dimensions.
+// Ratio is not constrained if 0
+func (b *Builder) WatermarkRatio(image string, x, y Position, alpha float64, wRatio, hRatio int) *Builder {
+	return b.Filter("watermark", escape(image), string(x), string(y), ftoa(alpha),
+		ratio(wRatio), ratio(hRatio))
+}
+
+// Layer composites the image path at position x, y with alpha transparency between 0 and 100,
+// and blend mode e.g. "multiply", "screen" or "overlay". Blend mode is "over" if empty
+func (b *Builder) Layer(image string, x, y Position, alpha float64, blend string) *Builder {
+	if blend == "" {
+		return b.Filter("layer", escape(image), string(x), string(y), ftoa(alpha))
+	}
+	return b.Filter("layer", escape(image), string(x), string(y), ftoa(alpha), blend)
+}
+
+// LayerSize Layer with the layer image fit within width and height, in pixels by Px or percentages
+// of the image dimensions by Percent, and rotated counterclockwise by angle in degrees.
+// Dimension is not constrained if empty
+func (b *Builder) LayerSize(
+	image string, x, y Position, alpha float64, blend string, width, height Position, angle float64,
+) *Builder {
+	if blend == "" {
+		blend = "over"
+	}
+	if width == "" {
+		width = "none"
+	}
+	if height == "" {
+		height = "none"
+	}
+	return b.Filter("layer", escape(image), string(x), string(y), ftoa(alpha), blend,
+		string(width), string(height), ftoa(angle))
+}
+
+// Label renders text at position x, y with font size, color and alpha transparency between 0 and 100
+func (b *Builder) Label(text string, x, y Position, size int, color string, alpha float64) *Builder {
+	return b.Filter("label", escape(text), string(x), string(y),
+		strconv.Itoa(size), colour(color), ftoa(alpha))
+}
+
+// LabelFont Label with font name
+func (b *Builder) LabelFont(text string, x, y Position, size int, color string, alpha float64, font string) *Builder {
+	return b.Filter("label", escape(text), string(x), string(y),
+		strconv.Itoa(size), colour(color), ftoa(alpha), escape(font))
+}
+
+// Text renders multi-line text at position x, y with font size, color, alpha transparency between 0 and 100
+// and font name. Options are in key:value form, e.g. "width:80p", "align:center", "line_height:1.5",
+// "stroke:2:black", "shadow:2:2:3:black:50", "background:black:8:12:30", "fontfile:fonts/Inter.ttf" or "markup"
+func (b *Builder) Text(
+	text string, x, y Position, size int, color string, alpha float64, font string, options ...string,
+) *Builder {
+	args := []string{escape(text), string(x), string(y), strconv.Itoa(size), colour(color), ftoa(alpha), escape(font)}
+	for _, option := range options {
+		args = append(args, escapeOption(option))
+	}
+	return b.Filter("text", args...)
+}
+
+// escapeOption escapes each value of key:value option
+func escapeOption(option string) string {
+	parts := strings.Split(option, ":")
+	for i, part := range parts {
+		parts[i] = escape(part)
+	}
+	return strings.Join(parts, ":")
+}
+
+// RoundCorner rounds the image corners by radius rx, ry with optional background color
+func (b *Builder) RoundCorner(rx, ry int, color string) *Builder {
+	if color != "" {
+		return b.Filter("round_corner", strconv.Itoa(rx), strconv.Itoa(ry), colour(color))
+	}
+	return b.Filter("round_corner", strconv.Itoa(rx), strconv.Itoa(ry))
+}
+
+// Border adds border of width in pixels with color around the image, "none" for transparent
+func (b *Builder) Border(width int, color string) *Builder {
+	return b.Filter("border", strconv.Itoa(width), colour(color))
+}
+
+// Shadow adds drop shadow offset by x, y with blur sigma, color and opacity between 0 and 100,
+// expanding the image canvas with transparent background
+func (b *Builder) Shadow(x, y int, sigma float64, color string, opacity float64) *Builder {
+	return b.Filter("shadow", strconv.Itoa(x), strconv.Itoa(y), ftoa(sigma), colour(color), ftoa(opacity))
+}
+
+// Vignette darkens the image edges by strength between 0 and 100
+func (b *Builder) Vignette(strength float64) *Builder {
+	return b.Filter("vignette", ftoa(strength))
+}
+
+// Mask applies the image path luminance, or alpha if available, as the image alpha channel,
+// scaled to fit and inverted if specified. Also accepts built-in shapes "circle", "ellipse", "hexagon",
+// or "path:" followed by SVG path data in 0 to 100 coordinates
+func (b *Builder) Mask(image string, invert bool) *Builder {
+	if invert {
+		return b.Filter("mask", escape(image), "invert")
+	}
+	return b.Filter("mask", escape(image))
+}
+
+// BackgroundColor sets the background color of transparent images
+func (b *Builder) BackgroundColor(color string) *Builder {
+	return b.Filter("background_color", colour(color))
+}
+
+// Grayscale converts the image to grayscale
+func (b *Builder) Grayscale() *Builder {
+	return b.Filter("grayscale")
+}
+
+// Brightness adjusts brightness by amount between -100 and 100
+func (b *Builder) Brightness(amount float64) *Builder {
+	return b.Filter("brightness", ftoa(amount))
+}
+
+// Contrast adjusts contrast by amount between -100 and 100
+func (b *Builder) Contrast(amount float64) *Builder {
+	return b.Filter("contrast", ftoa(amount))
+}
+
+// Hue rotates hue by angle in degrees
+func (b *Builder) Hue(angle float64) *Builder {
+	return b.Filter("hue", ftoa(angle))
+}
+
+// Saturation adjusts saturation by amount between -100 and 100
+func (b *Builder) Saturation(amount float64) *Builder {
+	return b.Filter("saturation", ftoa(amount))
+}
+
+// Modulate adjusts brightness, saturation and hue
+func (b *Builder) Modulate(brightness, saturation, hue float64) *Builder {
+	return b.Filter("modulate", ftoa(brightness), ftoa(saturation), ftoa(hue))
+}
+
+// Gamma applies gamma correction, brightens if greater than 1
+func (b *Builder) Gamma(gamma float64) *Builder {
+	return b.Filter("gamma", ftoa(gamma))
+}
+
+// Levels maps black and white input levels between 0 and 255 to the full range, with gamma of midtones
+func (b *Builder) Levels(black, white, gamma float64) *Builder {
+	if gamma == 0 || gamma == 1 {
+		return b.Filter("levels", ftoa(black), ftoa(white))
+	}
+	return b.Filter("levels", ftoa(black), ftoa(white), ftoa(gamma))
+}
+
+// Curves maps tones by smooth curve through x, y control points between 0 and 255
+func (b *Builder) Curves(points ...[2]float64) *Builder {
+	args := make([]string, len(points))
+	for i, p := range points {
+		args[i] = ftoa(p[0]) + ":" + ftoa(p[1])
+	}
+	return b.Filter("curves", args...)
+}
+
+// Normalize stretches luminance to the full range, clipping percentage of the darkest and brightest pixels
+func (b *Builder) Normalize(clip float64) *Builder {
+	return b.Filter("normalize", ftoa(clip))
+}
+
+// Equalize equalizes histogram of the luminance
+func (b *Builder) Equalize() *Builder {
+	return b.Filter("equalize")
+}
+
+// Sepia applies sepia tone by amount between 0 and 100
+func (b *Builder) Sepia(amount float64) *Builder {
+	return b.Filter("sepia", ftoa(amount))
+}
+
+// Duotone maps the image luminance between shadow and highlight colors
+func (b *Builder) Duotone(shadow, highlight string) *Builder {
+	return b.Filter("duotone", colour(shadow), colour(highlight))
+}
+
+// Colorize blends the image towards color by amount between 0 and 100
+func (b *Builder) Colorize(color string, amount float64) *Builder {
+	return b.Filter("colorize", colour(color), ftoa(amount))
+}
+
+// Gradient overlays linear gradient from color1 to color2 by angle in degrees clockwise from left to right,
+// with opacity between 0 and 100. Color "none" for transparent
+func (b *Builder) Gradient(angle float64, color1, color2 string, opacity float64) *Builder {
+	return b.Filter("gradient", ftoa(angle), colour(color1), colour(color2), ftoa(opacity))
+}
+
+// Transparent turns pixels within tolerance of color into transparency, tolerance and feather between 0 and 100.
+// Only areas connected to the image corners if flood, so that interior pixels of the color survive
+func (b *Builder) Transparent(color string, tolerance, feather float64, flood bool) *Builder {
+	if flood {
+		return b.Filter("transparent", colour(color), ftoa(tolerance), ftoa(feather), "flood")
+	}
+	return b.Filter("transparent", colour(color), ftoa(tolerance), ftoa(feather))
+}
+
+// RGB adjusts red, green and blue channels by amounts between -100 and 100
+func (b *Builder) RGB(r, g, bl float64) *Builder {
+	return b.Filter("rgb", ftoa(r), ftoa(g), ftoa(bl))
+}
+
+// Convolution convolves the image with matrix of columns in row-major order,
+// divided by the sum of the matrix if normalize
+func (b *Builder) Convolution(matrix []float64, columns int, normalize bool) *Builder {
+	items := make([]string, len(matrix))
+	for i, v := range matrix {
+		items[i] = ftoa(v)
+	}
+	return b.Filter("convolution", strings.Join(items, ";"), strconv.Itoa(columns), strconv.FormatBool(normalize))
+}
+
+// Blur applies gaussian blur with sigma
+func (b *Builder) Blur(sigma float64) *Builder {
+	return b.Filter("blur", ftoa(sigma))
+}
+
+// Sharpen sharpens the image with sigma
+func (b *Builder) Sharpen(sigma float64) *Builder {
+	return b.Filter("sharpen", ftoa(sigma))
+}
+
+// colour strips the hex color hash prefix, which is a URL fragment delimiter
+func colour(c string) string {
+	return strings.TrimPrefix(c, "#")
+}
+
+func ratio(n int) string {
+	if n <= 0 {
+		return "none"
+	}
+	return strconv.Itoa(n)
+}
@@ -4,8 +4,10 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/hex"
 	"flag"
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
 	"time"
@@ -41,6 +43,8 @@ func NewImagor(
 			"Output WebP format automatically if browser supports")
 		imagorAutoAVIF = fs.Bool("imagor-auto-avif", false,
 			"Output AVIF format automatically if browser supports (experimental)")
+		imagorAutoJXL = fs.Bool("imagor-auto-jxl", false,
+			"Output JPEG XL format automatically if browser supports")
 		imagorRequestTimeout = fs.Duration("imagor-request-timeout",
 			time.Second*30, "Timeout for performing imagor request")
 		imagorLoadTimeout = fs.Duration("imagor-load-timeout",
@@ -72,6 +76,26 @@ func NewImagor(
 		imagorSignerTruncate         = fs.Int("imagor-signer-truncate", 0, "imagor URL signature truncate at length")
 		imagorStoragePathStyle       = fs.String("imagor-storage-path-style", "original", "imagor storage path style: original, digest")
 		imagorResultStoragePathStyle = fs.String("imagor-result-storage-path-style", "original", "imagor result storage path style: original, digest, suffix")
+		imagorStrictFilters          = fs.Bool("imagor-strict-filters", false, "imagor strict filters that rejects unknown filters and invalid filter arguments with HTTP 400")
+		imagorCanonicalResultKey     = fs.Bool("imagor-canonical-result-key", false, "imagor canonical result key that deduplicates result storage and requests of equivalent params")
+		imagorDisableJSONParams      = fs.Bool("imagor-disable-json-params", false, "imagor disable JSON params POST request body")
+		imagorJSONParamsMaxBytes     = fs.Int64("imagor-json-params-max-bytes", 1<<20, "imagor maximum JSON params request body size in bytes")
+		imagorCardPathPrefix         = fs.String("imagor-card-path-prefix", "/card", "imagor social card endpoint path prefix")
+		imagorCardTemplates          = fs.String("imagor-card-templates", "", "imagor social card templates JSON file path, of templates by name")
+		imagorCardTemplateKeyPrefix  = fs.String("imagor-card-template-key-prefix", "", "imagor social card templates key prefix loaded from storages and loaders as {prefix}{name}.json. Disabled if empty")
+		imagorImgproxyPathPrefix     = fs.String("imagor-imgproxy-path-prefix", "", "imagor imgproxy compatible URL syntax path prefix e.g. /imgproxy. Disabled if empty")
+		imagorImgproxyKey            = fs.String("imagor-imgproxy-key", "", "imgproxy URL signature key in hex. Required unless imagor-unsafe")
+		imagorImgproxySalt           = fs.String("imagor-imgproxy-salt", "", "imgproxy URL signature salt in hex")
+		imagorImgproxySignatureSize  = fs.Int("imagor-imgproxy-signature-size", 32, "imgproxy URL signature size in bytes")
+		imagorImgproxyEncryptionKey  = fs.String("imagor-imgproxy-encryption-key", "", "imgproxy source URL AES-CBC encryption key in hex")
+		imagorStoragePathTemplate    = fs.String("imagor-storage-path-template", "",
+			"imagor storage path template e.g. originals/{image_digest:2}/{image}. Must contain {image} or {image_digest}. Overrides storage path style if specified")
+		imagorResultStoragePathTemplate = fs.String("imagor-result-storage-path-template", "",
+			"imagor result storage path template e.g. {image_dir}/{image_name}/{width}x{height}/{digest:16}.{ext}. Must contain {digest} of at least 16 characters. Overrides result storage path style if specified")
+		imagorMemoryBudget = fs.Int64("imagor-memory-budget", 0,
+			"imagor memory budget in bytes for buffering images, shared across all in-flight requests. Buffers spill to temp file beyond the budget. Unlimited if 0")
+		imagorMemoryThreshold = fs.Int64("imagor-memory-threshold", imagor.DefaultMemoryThreshold,
+			"imagor in-memory buffer threshold in bytes of images with unknown size. Buffers spill to temp file beyond the threshold")
 
 		options, logger, isDebug = applyOptions(fs, cb, append(funcs, baseConfig...)...)
 
@@ -98,6 +122,28 @@ func NewImagor(
 		resultHasher = imagorpath.SizeSuffixResultStorageHasher
 	}
 
+	if *imagorStoragePathTemplate != "" {
+		hasher = mustTemplateHasher(imagorpath.NewTemplateStorageHasher(*imagorStoragePathTemplate))
+	}
+	if *imagorResultStoragePathTemplate != "" {
+		resultHasher = mustTemplateHasher(imagorpath.NewTemplateResultStorageHasher(*imagorResultStoragePathTemplate))
+	}
+
+	var imgproxyParser *imagorpath.ImgproxyParser
+	if *imagorImgproxyPathPrefix != "" {
+		imgproxyParser = &imagorpath.ImgproxyParser{
+			Key:           mustDecodeHex(*imagorImgproxyKey),
+			Salt:          mustDecodeHex(*imagorImgproxySalt),
+			SignatureSize: *imagorImgproxySignatureSize,
+			EncryptionKey: mustDecodeHex(*imagorImgproxyEncryptionKey),
+		}
+	}
+
+	var cardTemplates map[string]imagorpath.CardTemplate
+	if *imagorCardTemplates != "" {
+		cardTemplates = mustCardTemplates(*imagorCardTemplates)
+	}
+
 	return imagor.New(append(
 		options,
 		imagor.WithSigner(imagorpath.NewHMACSigner(
@@ -116,6 +162,7 @@ func NewImagor(
 		imagor.WithCacheHeaderNoCache(*imagorCacheHeaderNoCache),
 		imagor.WithAutoWebP(*imagorAutoWebP),
 		imagor.WithAutoAVIF(*imagorAutoAVIF),
+		imagor.WithAutoJXL(*imagorAutoJXL),
 		imagor.WithModifiedTimeCheck(*imagorModifiedTimeCheck),
 		imagor.WithDisableErrorBody(*imagorDisableErrorBody),
 		imagor.WithDisableParamsEndpoint(*imagorDisableParamsEndpoint),
@@ -125,9 +172,49 @@ func NewImagor(
 		imagor.WithLogger(logger),
 		imagor.WithDebug(isDebug),
 		imagor.WithImageErrorFallback(*imagorImageErrorFallback),
+		imagor.WithImgproxy(*imagorImgproxyPathPrefix, imgproxyParser),
+		imagor.WithStrictFilters(*imagorStrictFilters),
+		imagor.WithCanonicalResultKey(*imagorCanonicalResultKey),
+		imagor.WithDisableJSONParams(*imagorDisableJSONParams),
+		imagor.WithJSONParamsMaxBytes(*imagorJSONParamsMaxBytes),
+		imagor.WithMemoryBudget(*imagorMemoryBudget),
+		imagor.WithMemoryThreshold(*imagorMemoryThreshold),
+		imagor.WithCardPathPrefix(*imagorCardPathPrefix),
+		imagor.WithCardTemplates(cardTemplates),
+		imagor.WithCardTemplateKeyPrefix(*imagorCardTemplateKeyPrefix),
 	)...)
 }
 
+func mustTemplateHasher(h *imagorpath.TemplateHasher, err error) *imagorpath.TemplateHasher {
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func mustCardTemplates(filename string) map[string]imagorpath.CardTemplate {
+	file, err := os.Open(filename)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	templates, err := imagorpath.ParseCardTemplates(file)
+	if err != nil {
+		panic(err)
+	}
+	return templates
+}
+
+func mustDecodeHex(s string) []byte {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
 // CreateServer create server from config flags. Returns nil on version or help command
 func CreateServer(args []string, funcs ...Option) (srv *server.Server) {
 	var (
@@ -238,6 +325,7 @@ func CreateServer(args []string, funcs ...Option) (srv *server.Server) {
 			prometheusmetrics.WithAddr(*prometheusBind),
 			prometheusmetrics.WithPath(*prometheusPath),
 			prometheusmetrics.WithLogger(logger),
+			prometheusmetrics.WithMemoryBudget(app.MemoryBudget),
 		)
 	}
 
@@ -8,6 +8,8 @@ import (
 	"github.com/kumparan/imagor/storage/filestorage"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -128,6 +130,57 @@ func TestSignerAlgorithm(t *testing.T) {
 	assert.Equal(t, "Kmml5ejnmsn7M7TszYkeM2j5G3bpI7mp", app.Signer.Sign("bar"))
 }
 
+func TestImgproxy(t *testing.T) {
+	srv := CreateServer([]string{})
+	app := srv.App.(*imagor.Imagor)
+	assert.Nil(t, app.ImgproxyParser)
+
+	srv = CreateServer([]string{
+		"-imagor-imgproxy-path-prefix", "/imgproxy",
+		"-imagor-imgproxy-key", "943b421c9eb07c830af81030552c86009268de4e532ba2ee2eab8247c6da0881",
+		"-imagor-imgproxy-salt", "520f986b998545b4785e0defbc4f3c1203f22de2374a3d53cb7a7fe9fea309c5",
+	})
+	app = srv.App.(*imagor.Imagor)
+	assert.Equal(t, "/imgproxy/", app.ImgproxyPathPrefix)
+	assert.Equal(t, "m3k5QADfcKPDj-SDI2AIogZbC3FlAXszuwhtWXYqavc",
+		app.ImgproxyParser.Sign("/rs:fit:300:300/plain/http://img.example.com/pretty/image.jpg"))
+
+	assert.Panics(t, func() {
+		CreateServer([]string{
+			"-imagor-imgproxy-path-prefix", "/imgproxy",
+			"-imagor-imgproxy-key", "foo",
+		})
+	})
+}
+
+func TestCardTemplates(t *testing.T) {
+	srv := CreateServer([]string{})
+	app := srv.App.(*imagor.Imagor)
+	assert.Empty(t, app.CardTemplates)
+	assert.Equal(t, "/card/", app.CardPathPrefix)
+
+	filename := filepath.Join(t.TempDir(), "cards.json")
+	assert.NoError(t, os.WriteFile(filename, []byte(
+		`{"article":{"width":1200,"height":630,"background":{"slot":"image"}}}`), 0644))
+	srv = CreateServer([]string{
+		"-imagor-card-templates", filename,
+		"-imagor-card-template-key-prefix", "cards/",
+		"-imagor-card-path-prefix", "/og",
+	})
+	app = srv.App.(*imagor.Imagor)
+	assert.Equal(t, 1200, app.CardTemplates["article"].Width)
+	assert.Equal(t, "cards/", app.CardTemplateKeyPrefix)
+	assert.Equal(t, "/og/", app.CardPathPrefix)
+
+	assert.Panics(t, func() {
+		CreateServer([]string{"-imagor-card-templates", filepath.Join(t.TempDir(), "missing.json")})
+	})
+	assert.NoError(t, os.WriteFile(filename, []byte(`{"article":{"width":0}}`), 0644))
+	assert.Panics(t, func() {
+		CreateServer([]string{"-imagor-card-templates", filename})
+	})
+}
+
 func TestCacheHeaderNoCache(t *testing.T) {
 	srv := CreateServer([]string{"-imagor-cache-header-no-cache"})
 	app := srv.App.(*imagor.Imagor)
@@ -146,12 +199,14 @@ func TestFileLoader(t *testing.T) {
 
 		"-file-loader-base-dir", "./foo",
 		"-file-loader-path-prefix", "abcd",
+		"-file-loader-mmap",
 	})
 	app := srv.App.(*imagor.Imagor)
 	fileLoader := app.Loaders[0].(*filestorage.FileStorage)
 	assert.Equal(t, "./foo", fileLoader.BaseDir)
 	assert.Equal(t, "/abcd/", fileLoader.PathPrefix)
 	assert.Equal(t, "!", fileLoader.SafeChars)
+	assert.True(t, fileLoader.Mmap)
 }
 
 func TestFileStorage(t *testing.T) {
@@ -197,6 +252,19 @@ func TestPathStyle(t *testing.T) {
 	})
 	app = srv.App.(*imagor.Imagor)
 	assert.Equal(t, "abc.30fdbe2aa5086e0f0c50_200x200", app.ResultStoragePathStyle.HashResult(imagorpath.Parse("200x200/abc")))
+
+	srv = CreateServer([]string{
+		"-imagor-result-storage-path-style", "digest",
+		"-imagor-storage-path-template", "originals/{image}",
+		"-imagor-result-storage-path-template", "{image_name}/{width}x{height}/{digest:16}.{ext}",
+	})
+	app = srv.App.(*imagor.Imagor)
+	assert.Equal(t, "originals/abc", app.StoragePathStyle.Hash("abc"))
+	assert.Equal(t, "abc/200x200/30fdbe2aa5086e0f", app.ResultStoragePathStyle.HashResult(imagorpath.Parse("200x200/abc")))
+
+	assert.Panics(t, func() {
+		CreateServer([]string{"-imagor-result-storage-path-template", "{image}/{foo}"})
+	})
 }
 
 func TestPrometheusBind(t *testing.T) {
@@ -16,6 +16,8 @@ func withFileSystem(fs *flag.FlagSet, cb func() (*zap.Logger, bool)) imagor.Opti
 			"Base directory for File Loader. Enable File Loader only if this value present")
 		fileLoaderPathPrefix = fs.String("file-loader-path-prefix", "",
 			"Base path prefix for File Loader")
+		fileLoaderMmap = fs.Bool("file-loader-mmap", false,
+			"File Loader reads files by memory-mapping for zero-copy processing")
 
 		fileStorageBaseDir = fs.String("file-storage-base-dir", "",
 			"Base directory for File Storage. Enable File Storage only if this value present")
@@ -27,6 +29,8 @@ func withFileSystem(fs *flag.FlagSet, cb func() (*zap.Logger, bool)) imagor.Opti
 			"File Storage write permission")
 		fileStorageExpiration = fs.Duration("file-storage-expiration", 0,
 			"File Storage expiration duration e.g. 24h. Default no expiration")
+		fileStorageMmap = fs.Bool("file-storage-mmap", false,
+			"File Storage reads files by memory-mapping for zero-copy processing. Files are written by atomic rename")
 
 		fileResultStorageBaseDir = fs.String("file-result-storage-base-dir", "",
 			"Base directory for File Result Storage. Enable File Result Storage only if this value present")
@@ -38,6 +42,8 @@ func withFileSystem(fs *flag.FlagSet, cb func() (*zap.Logger, bool)) imagor.Opti
 			"File Storage write permission")
 		fileResultStorageExpiration = fs.Duration("file-result-storage-expiration", 0,
 			"File Result Storage expiration duration e.g. 24h. Default no expiration")
+		fileResultStorageMmap = fs.Bool("file-result-storage-mmap", false,
+			"File Result Storage reads files by memory-mapping for zero-copy processing. Files are written by atomic rename")
 
 		_, _ = cb()
 	)
@@ -52,6 +58,7 @@ func withFileSystem(fs *flag.FlagSet, cb func() (*zap.Logger, bool)) imagor.Opti
 					filestorage.WithWritePermission(*fileStorageWritePermission),
 					filestorage.WithSafeChars(*fileSafeChars),
 					filestorage.WithExpiration(*fileStorageExpiration),
+					filestorage.WithMmap(*fileStorageMmap),
 				),
 			)
 		}
@@ -62,6 +69,7 @@ func withFileSystem(fs *flag.FlagSet, cb func() (*zap.Logger, bool)) imagor.Opti
 					*fileLoaderBaseDir,
 					filestorage.WithPathPrefix(*fileLoaderPathPrefix),
 					filestorage.WithSafeChars(*fileSafeChars),
+					filestorage.WithMmap(*fileLoaderMmap),
 				),
 			)
 		}
@@ -75,6 +83,7 @@ func withFileSystem(fs *flag.FlagSet, cb func() (*zap.Logger, bool)) imagor.Opti
 					filestorage.WithWritePermission(*fileResultStorageWritePermission),
 					filestorage.WithSafeChars(*fileSafeChars),
 					filestorage.WithExpiration(*fileResultStorageExpiration),
+					filestorage.WithMmap(*fileResultStorageMmap),
 				),
 			)
 		}
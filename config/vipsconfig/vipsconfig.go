@@ -36,6 +36,18 @@ func WithVips(fs *flag.FlagSet, cb func() (*zap.Logger, bool)) imagor.Option {
 			"VIPS enable maximum compression with MozJPEG. Requires mozjpeg to be installed")
 		vipsAvifSpeed = fs.Int("vips-avif-speed", 5,
 			"VIPS avif speed, the lowest is at 0 and the fastest is at 9 (Default 5).")
+		vipsJxlEffort = fs.Int("vips-jxl-effort", 7,
+			"VIPS JPEG XL encoder effort, the fastest is at 1 and the slowest is at 9 (Default 7).")
+		vipsMaxEffort = fs.Int("vips-max-effort", 6,
+			"VIPS maximum encoder effort allowed by effort filter, -1 for no limit")
+		vipsMaxLosslessResolution = fs.Int("vips-max-lossless-resolution", 4000000,
+			"VIPS maximum resolution of lossless AVIF, HEIF and JPEG XL output, -1 for no limit")
+		vipsFontDir = fs.String("vips-font-dir", "",
+			"VIPS directory for font files loaded by text filter. Default to temp directory")
+		vipsFontPrefix = fs.String("vips-font-prefix", "",
+			"VIPS allowed path prefix for font files loaded by text filter e.g. fonts/. Default any path")
+		vipsMaxFontFiles = fs.Int("vips-max-font-files", 100,
+			"VIPS maximum number of font files cached in font directory, least recently used are removed")
 		vipsStripMetadata = fs.Bool("vips-strip-metadata", false,
 			"VIPS strips all metadata from the resulting image")
 
@@ -56,6 +68,12 @@ func WithVips(fs *flag.FlagSet, cb func() (*zap.Logger, bool)) imagor.Option {
 			vips.WithMaxResolution(*vipsMaxResolution),
 			vips.WithMozJPEG(*vipsMozJPEG),
 			vips.WithAvifSpeed(*vipsAvifSpeed),
+			vips.WithJxlEffort(*vipsJxlEffort),
+			vips.WithMaxEffort(*vipsMaxEffort),
+			vips.WithMaxLosslessResolution(*vipsMaxLosslessResolution),
+			vips.WithFontDir(*vipsFontDir),
+			vips.WithFontPrefix(*vipsFontPrefix),
+			vips.WithMaxFontFiles(*vipsMaxFontFiles),
 			vips.WithStripMetadata(*vipsStripMetadata),
 			vips.WithLogger(logger),
 			vips.WithDebug(isDebug),
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -17,6 +19,7 @@ import (
 	"time"
 
 	"github.com/kumparan/imagor/imagorpath"
+	"github.com/kumparan/imagor/seekstream"
 	"go.uber.org/zap"
 	"golang.org/x/sync/semaphore"
 	"golang.org/x/sync/singleflight"
@@ -62,6 +65,23 @@ type Processor interface {
 	Shutdown(ctx context.Context) error
 }
 
+// FilterSpecProvider declares argument schema of filters supported by the Processor
+type FilterSpecProvider interface {
+	FilterSpecs() []imagorpath.FilterSpec
+}
+
+// FormatSupporter declares output formats supported by the Processor,
+// for Accept header based auto format negotiation
+type FormatSupporter interface {
+	SupportsFormat(format string) bool
+}
+
+// QualityDefaulter declares default export quality of output format by the Processor,
+// for canonical result key to remove quality same as the default
+type QualityDefaulter interface {
+	DefaultQuality(format string) int
+}
+
 // Imagor main application
 type Imagor struct {
 	Unsafe                 bool
@@ -83,6 +103,7 @@ type Imagor struct {
 	ProcessQueueSize       int64
 	AutoWebP               bool
 	AutoAVIF               bool
+	AutoJXL                bool
 	ModifiedTimeCheck      bool
 	DisableErrorBody       bool
 	DisableParamsEndpoint  bool
@@ -90,6 +111,18 @@ type Imagor struct {
 	Logger                 *zap.Logger
 	Debug                  bool
 	ImageErrorFallback     string
+	ImgproxyPathPrefix     string
+	ImgproxyParser         *imagorpath.ImgproxyParser
+	StrictFilters          bool
+	FilterSchema           imagorpath.FilterSchema
+	CanonicalResultKey     bool
+	DisableJSONParams      bool
+	JSONParamsMaxBytes     int64
+	CardPathPrefix         string
+	CardTemplates          map[string]imagorpath.CardTemplate
+	CardTemplateKeyPrefix  string
+	MemoryBudget           *seekstream.MemoryBudget
+	MemoryThreshold        int64
 
 	g          singleflight.Group
 	sema       *semaphore.Weighted
@@ -100,17 +133,27 @@ type Imagor struct {
 // New create new Imagor
 func New(options ...Option) *Imagor {
 	app := &Imagor{
-		Logger:         zap.NewNop(),
-		RequestTimeout: time.Second * 30,
-		LoadTimeout:    time.Second * 20,
-		SaveTimeout:    time.Second * 20,
-		ProcessTimeout: time.Second * 20,
-		CacheHeaderTTL: time.Hour * 24 * 7,
-		CacheHeaderSWR: time.Hour * 24,
+		Logger:             zap.NewNop(),
+		RequestTimeout:     time.Second * 30,
+		LoadTimeout:        time.Second * 20,
+		SaveTimeout:        time.Second * 20,
+		ProcessTimeout:     time.Second * 20,
+		CacheHeaderTTL:     time.Hour * 24 * 7,
+		CacheHeaderSWR:     time.Hour * 24,
+		FilterSchema:       imagorpath.DefaultFilterSchema,
+		JSONParamsMaxBytes: 1 << 20,
+		CardPathPrefix:     "/card/",
+		MemoryBudget:       seekstream.NewMemoryBudget(0),
+		MemoryThreshold:    DefaultMemoryThreshold,
 	}
 	for _, option := range options {
 		option(app)
 	}
+	for _, processor := range app.Processors {
+		if provider, ok := processor.(FilterSpecProvider); ok {
+			app.FilterSchema = app.FilterSchema.With(provider.FilterSpecs()...)
+		}
+	}
 	if app.ProcessConcurrency > 0 {
 		app.sema = semaphore.NewWeighted(app.ProcessConcurrency)
 		app.queueSema = semaphore.NewWeighted(app.ProcessQueueSize + app.ProcessConcurrency)
@@ -138,6 +181,32 @@ func (app *Imagor) Startup(ctx context.Context) (err error) {
 	return
 }
 
+// supportsFormat checks if output format supported by any Processor.
+// Assumes supported if no Processor implements FormatSupporter
+func (app *Imagor) supportsFormat(format string) bool {
+	var hasSupporter bool
+	for _, processor := range app.Processors {
+		if supporter, ok := processor.(FormatSupporter); ok {
+			if supporter.SupportsFormat(format) {
+				return true
+			}
+			hasSupporter = true
+		}
+	}
+	return !hasSupporter
+}
+
+// defaultQuality default export quality of output format by the first Processor implementing QualityDefaulter,
+// 0 if unknown
+func (app *Imagor) defaultQuality(format string) int {
+	for _, processor := range app.Processors {
+		if defaulter, ok := processor.(QualityDefaulter); ok {
+			return defaulter.DefaultQuality(format)
+		}
+	}
+	return 0
+}
+
 // Shutdown Imagor shutdown lifecycle
 func (app *Imagor) Shutdown(ctx context.Context) (err error) {
 	for _, processor := range app.Processors {
@@ -155,7 +224,9 @@ func (app *Imagor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	path := r.URL.EscapedPath()
-	if path == "/" || path == "" {
+	isJSONParams := (path == "/" || path == "") && r.Method == http.MethodPost &&
+		!app.DisableJSONParams && isJSONContentType(r)
+	if (path == "/" || path == "") && !isJSONParams {
 		if app.BasePathRedirect == "" {
 			w.Header().Set("Content-Type", "text/html")
 			_, _ = w.Write([]byte(landing))
@@ -164,20 +235,53 @@ func (app *Imagor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	p := imagorpath.Parse(path)
-	if p.Params {
-		if !app.DisableParamsEndpoint {
-			writeJSONIndent(w, r, p)
+	var p imagorpath.Params
+	var blob *Blob
+	var err error
+	if isJSONParams {
+		// JSON params request body
+		if p, err = app.parseJSONParams(w, r); err == nil {
+			blob, err = checkBlob(app.Do(r, p))
 		}
-		return
-	}
-	blob, err := checkBlob(app.Do(r, p))
-	if errors.Is(err, ErrInvalid) || errors.Is(err, ErrSignatureMismatch) {
-		if path2, e := url.QueryUnescape(path); e == nil {
-			path = path2
-			p = imagorpath.Parse(path)
+	} else if app.isCardEnabled() && strings.HasPrefix(path, app.CardPathPrefix) {
+		// social card template
+		if p, err = app.parseCard(w, r, strings.TrimPrefix(path, app.CardPathPrefix)); err == nil {
 			blob, err = checkBlob(app.Do(r, p))
 		}
+	} else if app.ImgproxyParser != nil && strings.HasPrefix(path, app.ImgproxyPathPrefix) {
+		// imgproxy compatible URL syntax
+		if len(app.ImgproxyParser.Key) == 0 && !app.Unsafe {
+			// unsigned imgproxy URLs only allowed in unsafe mode
+			err = ErrSignatureMismatch
+		} else if p, err = app.ImgproxyParser.Parse(strings.TrimPrefix(path, app.ImgproxyPathPrefix)); err == nil {
+			blob, err = checkBlob(app.Do(r, p))
+		} else if errors.Is(err, imagorpath.ErrImgproxySignatureMismatch) {
+			err = ErrSignatureMismatch
+		} else {
+			err = NewError(err.Error(), http.StatusBadRequest)
+		}
+	} else {
+		p = imagorpath.Parse(path)
+		if p.Params {
+			if !app.DisableParamsEndpoint {
+				res, e := app.typedParams(p)
+				if e != nil && app.StrictFilters {
+					w.WriteHeader(http.StatusBadRequest)
+					writeJSON(w, r, NewError(e.Error(), http.StatusBadRequest))
+					return
+				}
+				writeJSONIndent(w, r, res)
+			}
+			return
+		}
+		blob, err = checkBlob(app.Do(r, p))
+		if errors.Is(err, ErrInvalid) || errors.Is(err, ErrSignatureMismatch) {
+			if path2, e := url.QueryUnescape(path); e == nil {
+				path = path2
+				p = imagorpath.Parse(path)
+				blob, err = checkBlob(app.Do(r, p))
+			}
+		}
 	}
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
@@ -251,7 +355,31 @@ func (app *Imagor) Do(r *http.Request, p imagorpath.Params) (blob *Blob, err err
 		contextDefer(ctx, cancel)
 		r = r.WithContext(ctx)
 	}
-	if !(app.Unsafe && p.Unsafe) && app.Signer != nil && p.Path != "" {
+	var policy *imagorpath.Policy
+	if p.Policy != "" && p.Path != "" {
+		// policy signed URL, params are verified against the signed policy constraints
+		pol, e := imagorpath.ParsePolicy(p.Policy, app.Signer)
+		if e == nil {
+			e = pol.Verify(p, getClientIP(r))
+		}
+		if e != nil {
+			if errors.Is(e, imagorpath.ErrPolicyExpired) {
+				err = ErrExpired
+			} else if errors.Is(e, imagorpath.ErrPolicyViolation) {
+				err = NewError(e.Error(), http.StatusForbidden)
+			} else {
+				err = ErrSignatureMismatch
+			}
+			if app.Debug {
+				app.Logger.Debug("policy-mismatch", zap.Any("params", p), zap.Error(e))
+			}
+			return
+		}
+		if pol.Expire > 0 || pol.ClientIP != "" {
+			r.Header.Set("Cache-Control", "private")
+		}
+		policy = &pol
+	} else if !(app.Unsafe && p.Unsafe) && app.Signer != nil && p.Path != "" {
 		if hash := app.Signer.Sign(p.Path); hash != p.Hash {
 			err = ErrSignatureMismatch
 			if app.Debug {
@@ -261,6 +389,19 @@ func (app *Imagor) Do(r *http.Request, p imagorpath.Params) (blob *Blob, err err
 		}
 	}
 	var isPathChanged bool
+	if app.StrictFilters {
+		filters, e := app.FilterSchema.ValidateFilters(p.Filters)
+		if e != nil {
+			err = NewError(e.Error(), http.StatusBadRequest)
+			return
+		}
+		for i, f := range filters {
+			if f.Args != p.Filters[i].Args {
+				isPathChanged = true
+			}
+		}
+		p.Filters = filters
+	}
 	if app.BaseParams != "" {
 		p = imagorpath.Apply(p, app.BaseParams)
 		isPathChanged = true
@@ -296,28 +437,42 @@ func (app *Imagor) Do(r *http.Request, p imagorpath.Params) (blob *Blob, err err
 			p.Filters = append(p.Filters, f)
 		}
 	}
-	// auto WebP / AVIF
-	if !hasFormat && (app.AutoWebP || app.AutoAVIF) {
+	// auto WebP / AVIF / JPEG XL
+	if !hasFormat && (app.AutoWebP || app.AutoAVIF || app.AutoJXL) {
 		accept := r.Header.Get("Accept")
-		if app.AutoAVIF && strings.Contains(accept, "image/avif") {
-			p.Filters = append(p.Filters, imagorpath.Filter{
-				Name: "format",
-				Args: "avif",
-			})
-			r.Header.Set("Imagor-Auto-Format", "avif") // response Vary: Accept header
-			isPathChanged = true
-		} else if app.AutoWebP && strings.Contains(accept, "image/webp") {
+		var format string
+		for _, f := range []struct {
+			enabled bool
+			format  string
+		}{
+			{app.AutoJXL, "jxl"},
+			{app.AutoAVIF, "avif"},
+			{app.AutoWebP, "webp"},
+		} {
+			if f.enabled && strings.Contains(accept, "image/"+f.format) &&
+				(policy == nil || policy.AllowFormat(f.format)) && app.supportsFormat(f.format) {
+				format = f.format
+				break
+			}
+		}
+		if format != "" {
 			p.Filters = append(p.Filters, imagorpath.Filter{
 				Name: "format",
-				Args: "webp",
+				Args: format,
 			})
-			r.Header.Set("Imagor-Auto-Format", "webp") // response Vary: Accept header
+			r.Header.Set("Imagor-Auto-Format", format) // response Vary: Accept header
 			isPathChanged = true
 		}
 	}
 	if isPathChanged || p.Path == "" {
 		p.Path = imagorpath.GeneratePath(p)
 	}
+	// params of result key, processing uses the original params
+	var keyParams = p
+	if app.CanonicalResultKey {
+		// canonical path for result key deduplication, signature already checked against the original
+		keyParams = app.FilterSchema.Canonicalize(p, app.defaultQuality)
+	}
 	if p.Width < 0 {
 		p.Width = -p.Width
 		p.HFlip = !p.HFlip
@@ -329,12 +484,16 @@ func (app *Imagor) Do(r *http.Request, p imagorpath.Params) (blob *Blob, err err
 	var resultKey string
 	if p.Image != "" && !hasPreview {
 		if app.ResultStoragePathStyle != nil {
-			resultKey = app.ResultStoragePathStyle.HashResult(p)
+			resultKey = app.ResultStoragePathStyle.HashResult(keyParams)
 		} else {
-			resultKey = p.Path
+			resultKey = keyParams.Path
 		}
 	}
 	load := func(image string) (*Blob, error) {
+		if policy != nil && !policy.AllowImage(image) {
+			// images loaded by processor are within the policy prefix as well
+			return nil, NewError(fmt.Sprintf("%s: image not allowed", imagorpath.ErrPolicyViolation), http.StatusForbidden)
+		}
 		blob, _, err := app.loadStorage(r, image, false)
 		return blob, err
 	}
@@ -475,6 +634,112 @@ func (app *Imagor) loadResult(r *http.Request, resultKey, imageKey string) *Blob
 	return nil
 }
 
+func (app *Imagor) parseJSONParams(w http.ResponseWriter, r *http.Request) (p imagorpath.Params, err error) {
+	if p, err = imagorpath.ParseJSON(http.MaxBytesReader(w, r.Body, app.JSONParamsMaxBytes)); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = NewError("json params too large", http.StatusRequestEntityTooLarge)
+		} else {
+			err = NewError(err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	if !(app.Unsafe && p.Unsafe) && app.Signer != nil {
+		if hash := imagorpath.SignJSON(p, app.Signer); hash != p.Hash {
+			err = ErrSignatureMismatch
+			if app.Debug {
+				app.Logger.Debug("sign-mismatch", zap.Any("params", p), zap.String("expected", hash))
+			}
+			return
+		}
+	}
+	// signature covers the canonical JSON, with result key generated from params by Do
+	p.Hash = ""
+	p.Policy = ""
+	return
+}
+
+func (app *Imagor) isCardEnabled() bool {
+	return len(app.CardTemplates) > 0 || app.CardTemplateKeyPrefix != ""
+}
+
+// parseCard parse card endpoint of signature and template name,
+// rendering the template as Params by URL query values, or JSON object request body values
+func (app *Imagor) parseCard(w http.ResponseWriter, r *http.Request, path string) (p imagorpath.Params, err error) {
+	hash, name, _ := strings.Cut(path, "/")
+	if name, err = url.PathUnescape(name); err != nil || name == "" {
+		err = ErrInvalid
+		return
+	}
+	values := r.URL.Query()
+	if r.Method == http.MethodPost && isJSONContentType(r) {
+		var body map[string]string
+		if err = json.NewDecoder(http.MaxBytesReader(w, r.Body, app.JSONParamsMaxBytes)).Decode(&body); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				err = NewError("card values too large", http.StatusRequestEntityTooLarge)
+			} else {
+				err = NewError(fmt.Sprintf("%s: %s", imagorpath.ErrCardInvalid, err), http.StatusBadRequest)
+			}
+			return
+		}
+		for key, value := range body {
+			values.Set(key, value)
+		}
+	}
+	if !(app.Unsafe && hash == "unsafe") && app.Signer != nil {
+		if expected := app.Signer.Sign(imagorpath.CardPath(name, values)); expected != hash {
+			err = ErrSignatureMismatch
+			if app.Debug {
+				app.Logger.Debug("sign-mismatch", zap.String("card", name), zap.String("expected", expected))
+			}
+			return
+		}
+	}
+	t, err := app.cardTemplate(r, name)
+	if err != nil {
+		return
+	}
+	if p, err = t.Params(values); err != nil {
+		err = NewError(err.Error(), http.StatusBadRequest)
+	}
+	return
+}
+
+// cardTemplate returns card template by name from config,
+// or loaded from storages and loaders by template key prefix
+func (app *Imagor) cardTemplate(r *http.Request, name string) (t imagorpath.CardTemplate, err error) {
+	if t, ok := app.CardTemplates[name]; ok {
+		return t, nil
+	}
+	if app.CardTemplateKeyPrefix == "" {
+		err = ErrNotFound
+		return
+	}
+	r = app.requestWithLoadContext(r.WithContext(withContext(r.Context())))
+	blob, _, err := app.fromStoragesAndLoaders(
+		r, app.Storages, app.Loaders, app.CardTemplateKeyPrefix+name+".json", false)
+	if err != nil {
+		return
+	}
+	reader, _, err := blob.NewReader()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	if t, err = imagorpath.ParseCardTemplate(reader); err != nil {
+		err = NewError(err.Error(), http.StatusBadRequest)
+	}
+	return
+}
+
+func isJSONContentType(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/json"
+}
+
 func (app *Imagor) handleBase64(r *http.Request) (blob *Blob, err error) {
 	type supportedJSONField struct {
 		Base64 string `json:"base64"`
@@ -542,6 +807,11 @@ func (app *Imagor) loadStorage(r *http.Request, key string, isBase64 bool) (blob
 		blob.SetContentType(mimeType)
 		err = nil // reset error
 	}
+	if blob != nil {
+		// buffer within memory budget of the instance
+		blob.memoryBudget = app.MemoryBudget
+		blob.memoryThreshold = app.MemoryThreshold
+	}
 	return
 }
 
@@ -873,6 +1143,31 @@ func getContentDisposition(p imagorpath.Params, blob *Blob) string {
 	return "inline"
 }
 
+// typedParams Params with filters normalized and typed by the filter schema
+func (app *Imagor) typedParams(p imagorpath.Params) (res typedParams, err error) {
+	res.Params = p
+	for _, f := range p.Filters {
+		tf, e := app.FilterSchema.Validate(f)
+		if e != nil && err == nil {
+			err = e
+		}
+		res.Filters = append(res.Filters, tf)
+	}
+	return
+}
+
+type typedParams struct {
+	imagorpath.Params
+	Filters []imagorpath.TypedFilter `json:"filters,omitempty"`
+}
+
+func getClientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
 func getType(v interface{}) string {
 	t := reflect.TypeOf(v)
 	if t.Kind() == reflect.Ptr {
@@ -15,6 +15,8 @@ import (
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -264,6 +266,212 @@ func TestWithCustomSigner(t *testing.T) {
 	assert.Equal(t, w.Body.String(), jsonStr(ErrSignatureMismatch))
 }
 
+func TestWithPolicy(t *testing.T) {
+	signer := imagorpath.NewDefaultSigner("1234")
+	app := New(
+		WithDebug(true),
+		WithLogger(zap.NewExample()),
+		WithAutoWebP(true),
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			return NewBlobFromBytes([]byte("foo")), nil
+		})),
+		WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+			assert.NotContains(t, p.Path, "format(webp)")
+			return blob, nil
+		})),
+		WithSigner(signer))
+
+	token := imagorpath.GeneratePolicy(imagorpath.Policy{
+		Prefix:   "users/",
+		MaxWidth: 500,
+		Filters:  []string{"format", "quality"},
+		Formats:  []string{"jpeg"},
+	}, signer)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com/"+imagorpath.GenerateWithPolicy(imagorpath.Params{
+		Width: 300, Image: "users/foo.jpg",
+		Filters: imagorpath.Filters{{Name: "quality", Args: "70"}},
+	}, token), nil)
+	r.Header.Set("Accept", "image/webp")
+	app.ServeHTTP(w, r)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "foo", w.Body.String())
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/policy/"+token+"/600x0/users/foo.jpg", nil))
+	assert.Equal(t, 403, w.Code)
+	assert.Contains(t, w.Body.String(), "policy violation")
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/policy/"+token+"/300x0/admin/foo.jpg", nil))
+	assert.Equal(t, 403, w.Code)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/policy/"+token+"/300x0/filters:format(png)/users/foo.jpg", nil))
+	assert.Equal(t, 403, w.Code)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/policy/"+imagorpath.GeneratePolicy(imagorpath.Policy{}, imagorpath.NewDefaultSigner("abcd"))+"/users/foo.jpg", nil))
+	assert.Equal(t, 403, w.Code)
+	assert.Equal(t, w.Body.String(), jsonStr(ErrSignatureMismatch))
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/policy/"+imagorpath.GeneratePolicy(imagorpath.Policy{
+			Expire: time.Now().Add(-time.Second).UnixMilli(),
+		}, signer)+"/users/foo.jpg", nil))
+	assert.Equal(t, 410, w.Code)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/policy/"+imagorpath.GeneratePolicy(imagorpath.Policy{
+			ClientIP: "192.0.2.1",
+		}, signer)+"/users/foo.jpg", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Cache-Control"), "private"))
+}
+
+func TestWithPolicyProcessorLoad(t *testing.T) {
+	signer := imagorpath.NewDefaultSigner("1234")
+	app := New(
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			return NewBlobFromBytes([]byte(image)), nil
+		})),
+		WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+			// image loaded by filter argument
+			return load(p.Filters[0].Args)
+		})),
+		WithSigner(signer))
+	token := imagorpath.GeneratePolicy(imagorpath.Policy{Prefix: "users/"}, signer)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/policy/"+token+"/filters:custom(users/bar.jpg)/users/foo.jpg", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "users/bar.jpg", w.Body.String())
+
+	for _, image := range []string{"admin/bar.jpg", "users/../admin/bar.jpg"} {
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(
+			http.MethodGet, "https://example.com/policy/"+token+"/filters:custom("+image+")/users/foo.jpg", nil))
+		assert.Equal(t, 403, w.Code, image)
+		assert.Contains(t, w.Body.String(), "policy violation")
+	}
+}
+
+func TestWithImgproxy(t *testing.T) {
+	parser := &imagorpath.ImgproxyParser{Key: []byte("1234"), Salt: []byte("abcd")}
+	app := New(
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			return NewBlobFromBytes([]byte(image)), nil
+		})),
+		WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+			return NewBlobFromBytes([]byte(imagorpath.GeneratePath(p))), nil
+		})),
+		WithSigner(imagorpath.NewDefaultSigner("1234")),
+		WithImgproxy("imgproxy", parser))
+	assert.Equal(t, "/imgproxy/", app.ImgproxyPathPrefix)
+
+	path := "/rs:fill:300:200/g:sm/f:webp/plain/http://example.com/foo.jpg"
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/imgproxy/"+parser.Sign(path)+path, nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "300x200/smart/filters:no_upscale():format(webp)/http://example.com/foo.jpg", w.Body.String())
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/imgproxy/insecure"+path, nil))
+	assert.Equal(t, 403, w.Code)
+	assert.Equal(t, w.Body.String(), jsonStr(ErrSignatureMismatch))
+
+	path = "/pr:foo/plain/http://example.com/foo.jpg"
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/imgproxy/"+parser.Sign(path)+path, nil))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/unsafe/foo.jpg", nil))
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestWithImgproxyUnsigned(t *testing.T) {
+	newApp := func(options ...Option) *Imagor {
+		return New(append([]Option{
+			WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+				return NewBlobFromBytes([]byte(image)), nil
+			})),
+			WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+				return NewBlobFromBytes([]byte(imagorpath.GeneratePath(p))), nil
+			})),
+			WithImgproxy("imgproxy", &imagorpath.ImgproxyParser{}),
+		}, options...)...)
+	}
+	path := "/imgproxy/insecure/rs:fill:300:200/plain/http://example.com/foo.jpg"
+
+	app := newApp(WithSigner(imagorpath.NewDefaultSigner("1234")))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com"+path, nil))
+	assert.Equal(t, 403, w.Code)
+	assert.Equal(t, w.Body.String(), jsonStr(ErrSignatureMismatch))
+
+	app = newApp()
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com"+path, nil))
+	assert.Equal(t, 403, w.Code)
+
+	app = newApp(WithSigner(imagorpath.NewDefaultSigner("1234")), WithUnsafe(true))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com"+path, nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "300x200/filters:no_upscale()/http://example.com/foo.jpg", w.Body.String())
+}
+
+func TestWithMemoryBudget(t *testing.T) {
+	buf := bytes.Repeat([]byte("imagor"), 1000)
+	newApp := func(options ...Option) *Imagor {
+		return New(append([]Option{
+			WithUnsafe(true),
+			WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+				return NewBlob(func() (io.ReadCloser, int64, error) {
+					return io.NopCloser(bytes.NewReader(buf)), 0, nil
+				}), nil
+			})),
+			WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+				rs, _, err := blob.NewReadSeeker()
+				if err != nil {
+					return nil, err
+				}
+				defer rs.Close()
+				b, err := io.ReadAll(rs)
+				return NewBlobFromBytes(b), err
+			})),
+		}, options...)...)
+	}
+	app1 := newApp(WithMemoryBudget(100))
+	app2 := newApp(WithMemoryBudget(0), WithMemoryThreshold(1024))
+	assert.Equal(t, int64(100), app1.MemoryBudget.Limit())
+	assert.Equal(t, int64(0), app2.MemoryBudget.Limit())
+	assert.Equal(t, DefaultMemoryThreshold, app1.MemoryThreshold)
+	assert.Equal(t, int64(1024), app2.MemoryThreshold)
+
+	for _, app := range []*Imagor{app1, app2} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com/unsafe/foo.jpg", nil))
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, buf, w.Body.Bytes())
+		assert.Equal(t, int64(1), app.MemoryBudget.Spills())
+		assert.Zero(t, app.MemoryBudget.Used())
+	}
+}
+
 func TestWithRetryQueryUnescape(t *testing.T) {
 	opts := WithOptions(
 		WithDebug(true),
@@ -588,6 +796,76 @@ func TestParams(t *testing.T) {
 	assert.Empty(t, w.Body.String())
 }
 
+func TestStrictFilters(t *testing.T) {
+	app := New(
+		WithUnsafe(true),
+		WithStrictFilters(true),
+		WithFilterSpecs(imagorpath.FilterSpec{Name: "custom"}),
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			return NewBlobFromBytes([]byte("foo")), nil
+		})),
+		WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+			return NewBlobFromBytes([]byte(p.Path)), nil
+		})))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/unsafe/filters:quality(080):custom()/foo.jpg", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "filters:quality(80):custom()/foo.jpg", w.Body.String())
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/unsafe/filters:blurr(5)/foo.jpg", nil))
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, jsonStr(NewError("invalid filter blurr: unknown filter", 400)), w.Body.String())
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/unsafe/filters:quality(abc)/foo.jpg", nil))
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, jsonStr(NewError("invalid filter quality: argument amount expects an integer", 400)), w.Body.String())
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/params/unsafe/filters:quality(080):fill(FFF)/foo.jpg", nil))
+	assert.Equal(t, 200, w.Code)
+	var res struct {
+		Filters []imagorpath.TypedFilter `json:"filters"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, []imagorpath.TypedFilter{
+		{Filter: imagorpath.Filter{Name: "quality", Args: "80"}, TypedArgs: []interface{}{80.0}},
+		{Filter: imagorpath.Filter{Name: "fill", Args: "fff"}, TypedArgs: []interface{}{"fff"}},
+	}, res.Filters)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/params/unsafe/filters:blurr(5)/foo.jpg", nil))
+	assert.Equal(t, 400, w.Code)
+
+	app = New(
+		WithUnsafe(true),
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			return NewBlobFromBytes([]byte("foo")), nil
+		})))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/unsafe/filters:blurr(5)/foo.jpg", nil))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/params/unsafe/filters:blurr(5):quality(080)/foo.jpg", nil))
+	assert.Equal(t, 200, w.Code)
+	res.Filters = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, []imagorpath.TypedFilter{
+		{Filter: imagorpath.Filter{Name: "blurr", Args: "5"}},
+		{Filter: imagorpath.Filter{Name: "quality", Args: "80"}, TypedArgs: []interface{}{80.0}},
+	}, res.Filters)
+}
+
 func TestUseFallbackImageWhenLoadError(t *testing.T) {
 	loader := loaderFunc(func(r *http.Request, image string) (*Blob, error) {
 		return NewBlobFromFile("./non-exists-path"), nil
@@ -1043,6 +1321,251 @@ func TestWithResultStorageHasher(t *testing.T) {
 	assert.Equal(t, 1, len(resultStore.SaveCnt))
 }
 
+type qualityProcessor struct {
+	processorFunc
+}
+
+func (p qualityProcessor) DefaultQuality(format string) int {
+	if format == "webp" {
+		return 75
+	}
+	return 0
+}
+
+func TestWithCanonicalResultKey(t *testing.T) {
+	resultStore := newMapStore()
+	signer := imagorpath.NewDefaultSigner("1234")
+	app := New(
+		WithResultStorages(resultStore),
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			return NewBlobFromBytes([]byte(image)), nil
+		})),
+		WithProcessors(qualityProcessor{processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+			return NewBlobFromBytes([]byte(p.Path)), nil
+		})}),
+		WithSigner(signer),
+		WithCanonicalResultKey(true),
+	)
+	var paths = []string{
+		"fit-in/300x/filters:format(webp):grayscale():quality(75)/foo.jpg",
+		"fit-in/300x0/filters:grayscale():format(webp)/foo.jpg",
+		"fit-in/300x0/filters:grayscale():no_upscale():format(webp)/foo.jpg",
+	}
+	for _, path := range paths {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(
+			http.MethodGet, "https://example.com/"+signer.Sign(path)+"/"+path, nil))
+		time.Sleep(time.Millisecond * 10) // make sure storage reached
+		assert.Equal(t, 200, w.Code)
+		// processed with the original params, then shared by result storage
+		assert.Equal(t, paths[0], w.Body.String())
+	}
+	assert.Equal(t, 1, len(resultStore.SaveCnt))
+	assert.Equal(t, 1, resultStore.SaveCnt["fit-in/300x0/filters:grayscale():format(webp)/foo.jpg"])
+
+	// quality not the default is processed separately
+	path := "fit-in/300x0/filters:grayscale():format(webp):quality(80)/foo.jpg"
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/"+signer.Sign(path)+"/"+path, nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, path, w.Body.String())
+
+	// signature checked against the original path
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/"+signer.Sign("fit-in/300x0/filters:grayscale():format(webp)/foo.jpg")+
+			"/fit-in/300x/filters:format(webp):grayscale()/foo.jpg", nil))
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestJSONParams(t *testing.T) {
+	resultStore := newMapStore()
+	signer := imagorpath.NewDefaultSigner("1234")
+	app := New(
+		WithResultStorages(resultStore),
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			return NewBlobFromBytes([]byte(image)), nil
+		})),
+		WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+			return NewBlobFromBytes([]byte(p.Path)), nil
+		})),
+		WithSigner(signer),
+		WithJSONParamsMaxBytes(1024),
+	)
+	params := imagorpath.Params{
+		Image:  "foo.jpg",
+		FitIn:  true,
+		Width:  300,
+		Height: 200,
+		Filters: imagorpath.Filters{
+			{Name: "label", Args: "Hello%2C+World,center,bottom,30,white"},
+			{Name: "format", Args: "webp"},
+		},
+	}
+	doJSON := func(body string, contentType string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		app.ServeHTTP(w, r)
+		return w
+	}
+	w := doJSON(string(imagorpath.GenerateJSON(params, signer)), "application/json; charset=utf-8")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, imagorpath.GeneratePath(params), w.Body.String())
+	time.Sleep(time.Millisecond * 10) // make sure storage reached
+	assert.Equal(t, 1, resultStore.SaveCnt[imagorpath.GeneratePath(params)])
+
+	// same result key as the equivalent URL path
+	path := imagorpath.GeneratePath(params)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(
+		http.MethodGet, "https://example.com/"+signer.Sign(path)+"/"+path, nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, 1, len(resultStore.SaveCnt))
+
+	// signature covers the params
+	tampered := params
+	tampered.Width = 3000
+	tampered.Hash = imagorpath.SignJSON(params, signer)
+	buf, _ := json.Marshal(tampered)
+	w = doJSON(string(buf), "application/json")
+	assert.Equal(t, 403, w.Code)
+	assert.Equal(t, jsonStr(ErrSignatureMismatch), w.Body.String())
+
+	w = doJSON(string(imagorpath.GenerateJSON(params, nil)), "application/json")
+	assert.Equal(t, 403, w.Code)
+
+	w = doJSON("{", "application/json")
+	assert.Equal(t, 400, w.Code)
+
+	w = doJSON(`{"width":100}`, "application/json")
+	assert.Equal(t, 400, w.Code)
+
+	w = doJSON(`{"image":"`+strings.Repeat("a", 2048)+`"}`, "application/json")
+	assert.Equal(t, 413, w.Code)
+
+	// non JSON content type falls back to landing page
+	w = doJSON(string(imagorpath.GenerateJSON(params, signer)), "text/plain")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/html", w.Header().Get("Content-Type"))
+}
+
+func TestJSONParamsUnsafe(t *testing.T) {
+	app := New(
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			return NewBlobFromBytes([]byte(image)), nil
+		})),
+		WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+			return NewBlobFromBytes([]byte(p.Path)), nil
+		})),
+		WithUnsafe(true),
+	)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(
+		`{"image":"foo.jpg","width":100,"unsafe":true,"policy":"abc"}`))
+	r.Header.Set("Content-Type", "application/json")
+	app.ServeHTTP(w, r)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "100x0/foo.jpg", w.Body.String())
+
+	app = New(WithUnsafe(true), WithDisableJSONParams(true))
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(
+		`{"image":"foo.jpg","width":100,"unsafe":true}`))
+	r.Header.Set("Content-Type", "application/json")
+	app.ServeHTTP(w, r)
+	assert.Equal(t, "text/html", w.Header().Get("Content-Type"))
+}
+
+func TestCard(t *testing.T) {
+	resultStore := newMapStore()
+	store := newMapStore()
+	store.Map["cards/quote.json"] = NewBlobFromBytes([]byte(
+		`{"width":1080,"height":1080,"background":{"default":"bg.jpg"},"texts":[{"slot":"quote","size":48}]}`))
+	store.Map["cards/broken.json"] = NewBlobFromBytes([]byte(`{"width":0}`))
+	signer := imagorpath.NewDefaultSigner("1234")
+	app := New(
+		WithResultStorages(resultStore),
+		WithStorages(store),
+		WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+			if strings.HasPrefix(image, "cards/") {
+				return nil, ErrNotFound
+			}
+			return NewBlobFromBytes([]byte(image)), nil
+		})),
+		WithProcessors(processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+			return NewBlobFromBytes([]byte(p.Path)), nil
+		})),
+		WithSigner(signer),
+		WithCardPathPrefix("og"),
+		WithCardTemplates(map[string]imagorpath.CardTemplate{
+			"article": {
+				Width: 1200, Height: 630,
+				Background: imagorpath.CardImage{Slot: "image"},
+				Texts:      []imagorpath.CardText{{Slot: "title", X: "60", Y: "center", Size: 64, Color: "white"}},
+			},
+		}),
+		WithCardTemplateKeyPrefix("cards/"),
+		WithJSONParamsMaxBytes(1024),
+	)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		var r *http.Request
+		if body != "" {
+			r = httptest.NewRequest(method, "https://example.com"+path, strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+		} else {
+			r = httptest.NewRequest(method, "https://example.com"+path, nil)
+		}
+		app.ServeHTTP(w, r)
+		return w
+	}
+	values := url.Values{"title": {"Hello, World"}, "image": {"foo.jpg"}}
+	expected := "1200x630/filters:text(Hello%2C+World,60,center,64,white,0,)/foo.jpg"
+	w := do(http.MethodGet, "/og/"+imagorpath.GenerateCardPath("article", values, signer), "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, expected, w.Body.String())
+	time.Sleep(time.Millisecond * 10) // make sure storage reached
+	assert.Equal(t, 1, resultStore.SaveCnt[expected])
+
+	// JSON object request body values signed as query values
+	w = do(http.MethodPost, "/og/"+signer.Sign(imagorpath.CardPath("article", values))+"/article",
+		`{"title":"Hello, World","image":"foo.jpg"}`)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, expected, w.Body.String())
+
+	// template from storage
+	w = do(http.MethodGet, "/og/"+imagorpath.GenerateCardPath("quote", url.Values{"quote": {"Hi"}}, signer), "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "1080x1080/filters:text(Hi,,,48,,0,)/bg.jpg", w.Body.String())
+
+	w = do(http.MethodGet, "/og/"+imagorpath.GenerateCardPath("article", values, nil), "")
+	assert.Equal(t, 403, w.Code)
+	w = do(http.MethodGet, "/og/"+signer.Sign(imagorpath.CardPath("article", values))+"/article?title=foo&image=foo.jpg", "")
+	assert.Equal(t, 403, w.Code)
+	w = do(http.MethodGet, "/og/"+imagorpath.GenerateCardPath("article", url.Values{"title": {"foo"}}, signer), "")
+	assert.Equal(t, 400, w.Code)
+	w = do(http.MethodGet, "/og/"+imagorpath.GenerateCardPath("missing", values, signer), "")
+	assert.Equal(t, 404, w.Code)
+	w = do(http.MethodGet, "/og/"+imagorpath.GenerateCardPath("broken", values, signer), "")
+	assert.Equal(t, 400, w.Code)
+	w = do(http.MethodPost, "/og/"+imagorpath.GenerateCardPath("article", nil, signer), "{")
+	assert.Equal(t, 400, w.Code)
+	w = do(http.MethodPost, "/og/"+imagorpath.GenerateCardPath("article", nil, signer),
+		`{"title":"`+strings.Repeat("a", 2048)+`"}`)
+	assert.Equal(t, 413, w.Code)
+
+	// card endpoint disabled without templates
+	app = New(WithUnsafe(true), WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+		return NewBlobFromBytes([]byte(image)), nil
+	})))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com/unsafe/card/unsafe/article", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "card/unsafe/article", w.Body.String())
+}
+
 func TestWithStorageHasher(t *testing.T) {
 	var loadCnt = map[string]int{}
 	store := newMapStore()
@@ -1445,6 +1968,59 @@ func TestAutoAVIF(t *testing.T) {
 	})
 }
 
+type formatSupporterProcessor struct {
+	processorFunc
+	formats []string
+}
+
+func (p formatSupporterProcessor) SupportsFormat(format string) bool {
+	return slices.Contains(p.formats, format)
+}
+
+func TestAutoJXL(t *testing.T) {
+	process := processorFunc(func(ctx context.Context, blob *Blob, p imagorpath.Params, load LoadFunc) (*Blob, error) {
+		return NewBlobFromBytes([]byte(p.Path)), nil
+	})
+	factory := func(processor Processor) *Imagor {
+		return New(
+			WithUnsafe(true),
+			WithAutoWebP(true),
+			WithAutoAVIF(true),
+			WithAutoJXL(true),
+			WithLoaders(loaderFunc(func(r *http.Request, image string) (*Blob, error) {
+				return NewBlobFromBytes([]byte("foo")), nil
+			})),
+			WithProcessors(processor))
+	}
+	doTest := func(t *testing.T, app *Imagor, accept, expected string) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(
+			http.MethodGet, "https://example.com/unsafe/abc.png", nil)
+		r.Header.Set("Accept", accept)
+		app.ServeHTTP(w, r)
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, expected, w.Body.String())
+	}
+
+	t.Run("jxl preferred", func(t *testing.T) {
+		doTest(t, factory(process), "image/jxl,image/avif,image/webp,*/*",
+			"filters:format(jxl)/abc.png")
+	})
+	t.Run("jxl not accepted", func(t *testing.T) {
+		doTest(t, factory(process), "image/avif,image/webp,*/*",
+			"filters:format(avif)/abc.png")
+	})
+	t.Run("jxl not supported by processor", func(t *testing.T) {
+		app := factory(formatSupporterProcessor{process, []string{"webp", "avif"}})
+		doTest(t, app, "image/jxl,image/avif,image/webp,*/*",
+			"filters:format(avif)/abc.png")
+	})
+	t.Run("none supported by processor", func(t *testing.T) {
+		app := factory(formatSupporterProcessor{process, nil})
+		doTest(t, app, "image/jxl,image/avif,image/webp,*/*", "abc.png")
+	})
+}
+
 func TestWithTimeout(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if strings.Contains(r.URL.String(), "sleep") {
@@ -0,0 +1,224 @@
+package imagorpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrCardInvalid invalid card template or values error
+var ErrCardInvalid = errors.New("card invalid")
+
+// CardTemplate social card template, rendered as Params by filling slots with values
+type CardTemplate struct {
+	// Width Height canvas dimensions
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Format Quality output format and quality, optional
+	Format  string `json:"format,omitempty"`
+	Quality int    `json:"quality,omitempty"`
+	// Background image covering the canvas
+	Background CardImage `json:"background"`
+	// Texts text slots rendered in order
+	Texts []CardText `json:"texts,omitempty"`
+	// Images image slots e.g. logo, layered in order after texts
+	Images []CardImage `json:"images,omitempty"`
+}
+
+// CardImage card image slot
+type CardImage struct {
+	// Slot value name filling the image path
+	Slot string `json:"slot,omitempty"`
+	// Default image path if slot value is empty. Image slot is skipped if both empty
+	Default string `json:"default,omitempty"`
+	// X Y position, same as layer filter
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+	// Width Height fit within dimensions in pixels, or percentage with p suffix
+	Width  string  `json:"width,omitempty"`
+	Height string  `json:"height,omitempty"`
+	Alpha  float64 `json:"alpha,omitempty"`
+	Blend  string  `json:"blend,omitempty"`
+	// Fill background only, fits the image within canvas and fills the rest with color or blur,
+	// instead of cropping to cover
+	Fill string `json:"fill,omitempty"`
+	// Smart background only, smart crop to cover
+	Smart bool `json:"smart,omitempty"`
+}
+
+// CardText card text slot
+type CardText struct {
+	// Slot value name filling the text
+	Slot string `json:"slot,omitempty"`
+	// Default text if slot value is empty. Text slot is skipped if both empty
+	Default string `json:"default,omitempty"`
+	// X Y position, same as text filter
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+	// Width text box width for wrapping, in pixels or percentage with p suffix
+	Width      string  `json:"width,omitempty"`
+	Size       int     `json:"size,omitempty"`
+	Color      string  `json:"color,omitempty"`
+	Alpha      float64 `json:"alpha,omitempty"`
+	Font       string  `json:"font,omitempty"`
+	FontFile   string  `json:"font_file,omitempty"`
+	Align      string  `json:"align,omitempty"`
+	LineHeight float64 `json:"line_height,omitempty"`
+	// MaxLength truncates text with ellipsis by number of characters
+	MaxLength int `json:"max_length,omitempty"`
+	// Stroke Shadow Background text filter option values
+	// e.g. "2:000000", "2:2:3:000000:50" with shadow opacity 50, "000000:8:12:30" with background alpha 30
+	Stroke     string `json:"stroke,omitempty"`
+	Shadow     string `json:"shadow,omitempty"`
+	Background string `json:"background,omitempty"`
+}
+
+// ParseCardTemplates parse JSON object of card templates by name
+func ParseCardTemplates(r io.Reader) (map[string]CardTemplate, error) {
+	var templates map[string]CardTemplate
+	if err := json.NewDecoder(r).Decode(&templates); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCardInvalid, err)
+	}
+	for name, t := range templates {
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("%w: template %s", err, name)
+		}
+	}
+	return templates, nil
+}
+
+// ParseCardTemplate parse JSON card template
+func ParseCardTemplate(r io.Reader) (t CardTemplate, err error) {
+	if err = json.NewDecoder(r).Decode(&t); err != nil {
+		err = fmt.Errorf("%w: %w", ErrCardInvalid, err)
+		return
+	}
+	err = t.validate()
+	return
+}
+
+func (t CardTemplate) validate() error {
+	if t.Width <= 0 || t.Height <= 0 {
+		return fmt.Errorf("%w: invalid dimensions", ErrCardInvalid)
+	}
+	if t.Background.Slot == "" && t.Background.Default == "" {
+		return fmt.Errorf("%w: missing background", ErrCardInvalid)
+	}
+	return nil
+}
+
+// Params render card template as Params by slot values
+func (t CardTemplate) Params(values url.Values) (p Params, err error) {
+	if err = t.validate(); err != nil {
+		return
+	}
+	bg := t.Background
+	p.Image = slotValue(values, bg.Slot, bg.Default)
+	if p.Image == "" {
+		err = fmt.Errorf("%w: missing %s", ErrCardInvalid, bg.Slot)
+		return
+	}
+	p.Width = t.Width
+	p.Height = t.Height
+	p.Smart = bg.Smart
+	if bg.Fill != "" {
+		p.FitIn = true
+		p.Filters = append(p.Filters,
+			Filter{Name: "upscale"},
+			Filter{Name: "fill", Args: strings.TrimPrefix(bg.Fill, "#")},
+		)
+	}
+	for _, text := range t.Texts {
+		if s := slotValue(values, text.Slot, text.Default); s != "" {
+			p.Filters = append(p.Filters, text.filter(s))
+		}
+	}
+	for _, img := range t.Images {
+		if s := slotValue(values, img.Slot, img.Default); s != "" {
+			p.Filters = append(p.Filters, img.filter(s))
+		}
+	}
+	if t.Format != "" {
+		p.Filters = append(p.Filters, Filter{Name: "format", Args: t.Format})
+	}
+	if t.Quality > 0 {
+		p.Filters = append(p.Filters, Filter{Name: "quality", Args: strconv.Itoa(t.Quality)})
+	}
+	return
+}
+
+func (t CardText) filter(text string) Filter {
+	if t.MaxLength > 0 && utf8.RuneCountInString(text) > t.MaxLength {
+		text = strings.TrimSpace(string([]rune(text)[:t.MaxLength])) + "…"
+	}
+	args := []string{
+		url.QueryEscape(text), t.X, t.Y, strconv.Itoa(t.Size),
+		strings.TrimPrefix(t.Color, "#"), ftoa(t.Alpha), url.QueryEscape(t.Font),
+	}
+	for _, option := range []struct{ key, value string }{
+		{"width", t.Width},
+		{"align", t.Align},
+		{"line_height", ftoa(t.LineHeight)},
+		{"stroke", t.Stroke},
+		{"shadow", t.Shadow},
+		{"background", t.Background},
+		{"fontfile", url.QueryEscape(t.FontFile)},
+	} {
+		if option.value != "" && option.value != "0" {
+			args = append(args, option.key+":"+option.value)
+		}
+	}
+	return Filter{Name: "text", Args: strings.Join(args, ",")}
+}
+
+func (i CardImage) filter(image string) Filter {
+	blend := i.Blend
+	if blend == "" {
+		blend = "over"
+	}
+	args := []string{url.QueryEscape(image), i.X, i.Y, ftoa(i.Alpha), blend, orNone(i.Width), orNone(i.Height)}
+	return Filter{Name: "layer", Args: strings.Join(args, ",")}
+}
+
+// CardPath canonical card path of template name and values for signing
+func CardPath(name string, values url.Values) string {
+	if len(values) == 0 {
+		return name
+	}
+	return name + "?" + values.Encode()
+}
+
+// GenerateCardPath generate card endpoint path of template name and values with signer,
+// to be appended to the card path prefix. Unsafe if signer is nil
+func GenerateCardPath(name string, values url.Values, signer Signer) string {
+	path := CardPath(name, values)
+	if signer == nil {
+		return "unsafe/" + path
+	}
+	return signer.Sign(path) + "/" + path
+}
+
+func slotValue(values url.Values, slot, def string) string {
+	if slot != "" {
+		if s := strings.TrimSpace(values.Get(slot)); s != "" {
+			return s
+		}
+	}
+	return def
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
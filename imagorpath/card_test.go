@@ -0,0 +1,93 @@
+package imagorpath
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCardTemplates = `{
+	"article": {
+		"width": 1200,
+		"height": 630,
+		"format": "jpeg",
+		"quality": 85,
+		"background": {"slot": "image", "default": "cards/default.jpg", "smart": true},
+		"texts": [
+			{"slot": "title", "x": "60", "y": "center", "width": "80p", "size": 64, "color": "#ffffff",
+			 "font": "Inter Bold", "font_file": "fonts/Inter-Bold.ttf", "line_height": 1.2, "max_length": 20,
+			 "shadow": "2:2:4:000000:50"},
+			{"slot": "author", "default": "imagor", "x": "60", "y": "-60", "size": 28, "color": "ccc"}
+		],
+		"images": [
+			{"slot": "logo", "default": "logo.png", "x": "right", "y": "top", "width": "15p"}
+		]
+	},
+	"quote": {
+		"width": 1080,
+		"height": 1080,
+		"background": {"slot": "image", "fill": "blur"},
+		"texts": [{"slot": "quote", "x": "center", "y": "center", "size": 48, "align": "center", "background": "000000:16:12:30"}]
+	}
+}`
+
+func TestCardTemplate(t *testing.T) {
+	templates, err := ParseCardTemplates(strings.NewReader(testCardTemplates))
+	assert.NoError(t, err)
+	assert.Len(t, templates, 2)
+
+	p, err := templates["article"].Params(url.Values{
+		"title": {"Hello, World (1) of a very long title"},
+		"image": {"https://example.com/photo.jpg?v=1"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"1200x630/smart/filters:"+
+			"text(Hello%2C+World+%281%29+of%E2%80%A6,60,center,64,ffffff,0,Inter+Bold,width:80p,line_height:1.2,shadow:2:2:4:000000:50,fontfile:fonts%2FInter-Bold.ttf):"+
+			"text(imagor,60,-60,28,ccc,0,):"+
+			"layer(logo.png,right,top,0,over,15p,none):"+
+			"format(jpeg):quality(85)/"+
+			"https%3A%2F%2Fexample.com%2Fphoto.jpg%3Fv%3D1",
+		GeneratePath(p))
+
+	p, err = templates["article"].Params(url.Values{"author": {"  "}, "logo": {"brand/logo.png"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "cards/default.jpg", p.Image)
+	assert.Equal(t, "text(imagor,60,-60,28,ccc,0,)", "text("+p.Filters[0].Args+")")
+	assert.Equal(t, "brand%2Flogo.png,right,top,0,over,15p,none", p.Filters[1].Args)
+
+	p, err = templates["quote"].Params(url.Values{"image": {"bg.jpg"}, "quote": {"Stay hungry"}})
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"fit-in/1080x1080/filters:upscale():fill(blur):text(Stay+hungry,center,center,48,,0,,align:center,background:000000:16:12:30)/bg.jpg",
+		GeneratePath(p))
+
+	_, err = templates["quote"].Params(url.Values{"quote": {"Stay hungry"}})
+	assert.ErrorIs(t, err, ErrCardInvalid)
+}
+
+func TestParseCardTemplatesInvalid(t *testing.T) {
+	for _, body := range []string{
+		`{`,
+		`{"a": {"width": 0, "height": 100, "background": {"default": "a.jpg"}}}`,
+		`{"a": {"width": 100, "height": 100}}`,
+	} {
+		_, err := ParseCardTemplates(strings.NewReader(body))
+		assert.ErrorIs(t, err, ErrCardInvalid, body)
+	}
+	tmpl, err := ParseCardTemplate(strings.NewReader(`{"width": 100, "height": 50, "background": {"slot": "image"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 100, tmpl.Width)
+}
+
+func TestGenerateCardPath(t *testing.T) {
+	signer := NewDefaultSigner("1234")
+	values := url.Values{"title": {"Hello World"}, "author": {"foo"}}
+	assert.Equal(t, "article?author=foo&title=Hello+World", CardPath("article", values))
+	assert.Equal(t, "unsafe/article?author=foo&title=Hello+World", GenerateCardPath("article", values, nil))
+	assert.Equal(t, signer.Sign(CardPath("article", values))+"/article?author=foo&title=Hello+World",
+		GenerateCardPath("article", values, signer))
+	assert.Equal(t, "unsafe/article", GenerateCardPath("article", nil, nil))
+}
@@ -55,3 +55,69 @@ func TestSuffixResultStorageHasher(t *testing.T) {
 	assert.Equal(t, "example.com/foobar.c80ab0faf85b35a140a8.json", SuffixResultStorageHasher.HashResult(p))
 	assert.Equal(t, "example.com/foobar.c80ab0faf85b35a140a8_17x19.json", SizeSuffixResultStorageHasher.HashResult(p))
 }
+
+func TestTemplateHasher(t *testing.T) {
+	h, err := NewTemplateResultStorageHasher("{image_dir}/{image_name}/{width}x{height}/{digest:16}.{ext}")
+	assert.NoError(t, err)
+	assert.Equal(t, "foobar/16x17/d5c2804e5d81c475", h.HashResult(Parse("fit-in/16x17/foobar")))
+	assert.Equal(t, "foobar/166x169/45d8ebb31bd4ed80.jpg", h.HashResult(Parse("166x169/top/foobar.jpg")))
+	assert.Equal(t, "example.com/foobar/17x19/ddd349e092cda6d9", h.HashResult(Parse("17x19/smart/example.com/foobar")))
+	p := Params{
+		Smart: true, Width: 17, Height: 19, Image: "example.com/foobar.jpg",
+		Filters: []Filter{{"format", "webp"}},
+	}
+	assert.Equal(t, "example.com/foobar/17x19/8aade9060badfcb2.webp", h.HashResult(p))
+	p.Meta = true
+	assert.Equal(t, "json", h.HashResult(p)[len(h.HashResult(p))-4:])
+	assert.Equal(t, "{image_dir}/{image_name}/{width}x{height}/{digest:16}.{ext}", h.String())
+
+	h, err = NewTemplateResultStorageHasher("results/{image_digest:4}/{image}/{digest}")
+	assert.NoError(t, err)
+	assert.Equal(t, "results/8843/foobar/d5c2804e5d81c475bee50f731db17ee613f43262", h.HashResult(Parse("fit-in/16x17/foobar")))
+
+	s, err := NewTemplateStorageHasher("originals/{image_ext}/{image}")
+	assert.NoError(t, err)
+	assert.Equal(t, "originals/jpg/a/b/c.jpg", s.Hash("a/b/c.jpg"))
+	assert.Equal(t, "originals/c", s.Hash("c"))
+	assert.Equal(t, "originals/png/https://example.com/a//b.png", s.Hash("https://example.com/a//b.png"))
+
+	s, err = NewTemplateStorageHasher("{image_dir}/{image_name}.{image_ext}/{image}")
+	assert.NoError(t, err)
+	assert.Equal(t, "c.jpg/c.jpg", s.Hash("c.jpg"))
+	assert.Equal(t, "c/c", s.Hash("c"))
+	assert.Equal(t, "https://example.com/b.png/https://example.com/b.png", s.Hash("https://example.com/b.png"))
+
+	s, err = NewTemplateStorageHasher("{image_digest:2}/{image_digest}.{image_ext}")
+	assert.NoError(t, err)
+	assert.Equal(t, "e1/e11fbae8a1884d46e4e8bcb3fa33f6c8a015a193.jpg", s.Hash("a/b/c.jpg"))
+
+	for _, tmpl := range []string{
+		"{image}",
+		"{digest",
+		"digest}",
+		"{foo}/{digest}",
+		"{digest:0}",
+		"{digest:41}",
+		"{digest:1}",
+		"{digest:4}",
+		"{digest:15}/{image}",
+		"{digest:abc}",
+		"{width:2}/{digest}",
+	} {
+		_, err = NewTemplateResultStorageHasher(tmpl)
+		assert.Error(t, err, tmpl)
+	}
+	for _, tmpl := range []string{
+		"static",
+		"{digest}",
+		"{image}/{width}",
+		"{image_name}.{image_ext}",
+		"{image_dir}/x",
+		"{image_dir}/{image_name}.{image_ext}",
+		"{image_digest:8}/{image_name}",
+		"{image_digest:15}",
+	} {
+		_, err = NewTemplateStorageHasher(tmpl)
+		assert.Error(t, err, tmpl)
+	}
+}
@@ -0,0 +1,343 @@
+package imagorpath
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrImgproxySignatureMismatch imgproxy URL signature mismatch error
+	ErrImgproxySignatureMismatch = errors.New("imgproxy signature mismatch")
+	// ErrImgproxyInvalid imgproxy URL invalid error
+	ErrImgproxyInvalid = errors.New("imgproxy invalid")
+)
+
+// ImgproxyParser imgproxy compatible URL parser,
+// mapping imgproxy processing options into Params
+type ImgproxyParser struct {
+	// Key imgproxy signature key. Signature check skipped if empty,
+	// which imagor only allows in unsafe mode
+	Key []byte
+	// Salt imgproxy signature salt
+	Salt []byte
+	// SignatureSize imgproxy signature truncate size in bytes
+	SignatureSize int
+	// EncryptionKey imgproxy AES-CBC source URL encryption key
+	EncryptionKey []byte
+}
+
+// Parse Params from imgproxy URI, with signature verified
+func (ip *ImgproxyParser) Parse(path string) (p Params, err error) {
+	path = "/" + strings.TrimLeft(path, "/")
+	idx := strings.Index(path[1:], "/")
+	if idx < 0 {
+		err = ErrImgproxyInvalid
+		return
+	}
+	sig, rest := path[1:idx+1], path[idx+1:]
+	if len(ip.Key) > 0 {
+		if !hmac.Equal([]byte(sig), []byte(ip.Sign(rest))) {
+			err = ErrImgproxySignatureMismatch
+			return
+		}
+	}
+	var (
+		rt      = "fit"
+		width   int
+		height  int
+		dpr     = 1.0
+		enlarge bool
+		extend  bool
+		bg      string
+		format  string
+		source  string
+		ext     string
+	)
+	segments := strings.Split(rest[1:], "/")
+	for i, seg := range segments {
+		if seg == "plain" {
+			source, ext = parsePlainSource(strings.Join(segments[i+1:], "/"))
+			break
+		} else if seg == "enc" {
+			if source, ext, err = ip.parseEncryptedSource(strings.Join(segments[i+1:], "")); err != nil {
+				return
+			}
+			break
+		}
+		name, arg, isOption := strings.Cut(seg, ":")
+		if !isOption {
+			if source, ext, err = parseBase64Source(strings.Join(segments[i:], "")); err != nil {
+				return
+			}
+			break
+		}
+		args := strings.Split(arg, ":")
+		switch name {
+		case "resize", "rs":
+			rt = args[0]
+			width, height, enlarge, extend = parseImgproxySize(args[1:], width, height, enlarge, extend)
+		case "size", "s":
+			width, height, enlarge, extend = parseImgproxySize(args, width, height, enlarge, extend)
+		case "resizing_type", "rt":
+			rt = args[0]
+		case "width", "w":
+			width, _ = strconv.Atoi(args[0])
+		case "height", "h":
+			height, _ = strconv.Atoi(args[0])
+		case "dpr":
+			if dpr, err = strconv.ParseFloat(args[0], 64); err != nil || dpr <= 0 {
+				err = fmt.Errorf("%w: dpr %s", ErrImgproxyInvalid, arg)
+				return
+			}
+		case "enlarge", "el":
+			enlarge = parseImgproxyBool(args[0])
+		case "extend", "ex":
+			extend = parseImgproxyBool(args[0])
+			if len(args) > 1 {
+				setImgproxyGravity(&p, args[1:])
+			}
+		case "gravity", "g":
+			setImgproxyGravity(&p, args)
+		case "quality", "q":
+			p.Filters = append(p.Filters, Filter{Name: "quality", Args: args[0]})
+		case "format", "f", "ext":
+			format = args[0]
+		case "background", "bg":
+			if bg, err = parseImgproxyColor(args); err != nil {
+				return
+			}
+			p.Filters = append(p.Filters, Filter{Name: "background_color", Args: bg})
+		case "blur", "bl":
+			p.Filters = append(p.Filters, Filter{Name: "blur", Args: args[0]})
+		case "sharpen", "sh":
+			p.Filters = append(p.Filters, Filter{Name: "sharpen", Args: args[0]})
+		case "rotate", "rot":
+			// imgproxy rotates clockwise, imagor rotates counterclockwise
+			angle, _ := strconv.Atoi(args[0])
+			if angle = (360 - angle%360) % 360; angle != 0 {
+				p.Filters = append(p.Filters, Filter{Name: "rotate", Args: strconv.Itoa(angle)})
+			}
+		case "max_bytes", "mb":
+			p.Filters = append(p.Filters, Filter{Name: "max_bytes", Args: args[0]})
+		case "strip_metadata", "sm":
+			if parseImgproxyBool(args[0]) {
+				p.Filters = append(p.Filters, Filter{Name: "strip_metadata"})
+			}
+		case "padding", "pd":
+			// CSS-like shorthand: top, right, bottom, left
+			var pd [4]int
+			for j := range args {
+				if j < len(pd) {
+					pd[j], _ = strconv.Atoi(args[j])
+				}
+			}
+			p.PaddingTop, p.PaddingRight, p.PaddingBottom, p.PaddingLeft = pd[0], pd[0], pd[0], pd[0]
+			if len(args) > 1 {
+				p.PaddingRight, p.PaddingLeft = pd[1], pd[1]
+			}
+			if len(args) > 2 {
+				p.PaddingBottom = pd[2]
+			}
+			if len(args) > 3 {
+				p.PaddingLeft = pd[3]
+			}
+		case "trim", "t":
+			p.Trim = true
+			p.TrimTolerance, _ = strconv.Atoi(args[0])
+		case "cachebuster", "cb", "expires", "exp":
+			// no-op
+		default:
+			err = fmt.Errorf("%w: unsupported option %s", ErrImgproxyInvalid, name)
+			return
+		}
+	}
+	if source == "" {
+		err = fmt.Errorf("%w: missing source url", ErrImgproxyInvalid)
+		return
+	}
+	p.Image = source
+	if ext != "" {
+		format = ext
+	}
+	p.Width = int(float64(width) * dpr)
+	p.Height = int(float64(height) * dpr)
+	switch rt {
+	case "fit":
+		p.FitIn = true
+		if enlarge {
+			p.Filters = append(p.Filters, Filter{Name: "upscale"})
+		}
+	case "fill", "auto":
+		if !enlarge {
+			p.Filters = append(p.Filters, Filter{Name: "no_upscale"})
+		}
+	case "fill-down":
+		p.Filters = append(p.Filters, Filter{Name: "no_upscale"})
+	case "force":
+		p.Stretch = true
+	default:
+		err = fmt.Errorf("%w: unsupported resizing type %s", ErrImgproxyInvalid, rt)
+		return
+	}
+	if extend && p.FitIn && p.Width > 0 && p.Height > 0 {
+		if bg == "" {
+			bg = "none"
+		}
+		p.Filters = append(p.Filters, Filter{Name: "fill", Args: bg})
+	}
+	if format != "" {
+		if format == "jpg" {
+			format = "jpeg"
+		}
+		p.Filters = append(p.Filters, Filter{Name: "format", Args: format})
+	}
+	return
+}
+
+// Sign imgproxy signature of the path, including the leading slash
+func (ip *ImgproxyParser) Sign(path string) string {
+	h := hmac.New(sha256.New, ip.Key)
+	h.Write(ip.Salt)
+	h.Write([]byte(path))
+	sum := h.Sum(nil)
+	if ip.SignatureSize > 0 && ip.SignatureSize < len(sum) {
+		sum = sum[:ip.SignatureSize]
+	}
+	return base64.RawURLEncoding.EncodeToString(sum)
+}
+
+func parsePlainSource(s string) (source, format string) {
+	if idx := strings.LastIndex(s, "@"); idx >= 0 {
+		s, format = s[:idx], s[idx+1:]
+	}
+	source = s
+	if u, err := url.PathUnescape(s); err == nil {
+		source = u
+	}
+	return
+}
+
+func (ip *ImgproxyParser) parseEncryptedSource(s string) (source, format string, err error) {
+	s, format = splitImgproxyExtension(s)
+	buf, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil || len(ip.EncryptionKey) == 0 ||
+		len(buf) < aes.BlockSize*2 || len(buf)%aes.BlockSize != 0 {
+		err = fmt.Errorf("%w: encrypted source url", ErrImgproxyInvalid)
+		return
+	}
+	block, err := aes.NewCipher(ip.EncryptionKey)
+	if err != nil {
+		err = fmt.Errorf("%w: encryption key", ErrImgproxyInvalid)
+		return
+	}
+	iv, data := buf[:aes.BlockSize], buf[aes.BlockSize:]
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(data, data)
+	// PKCS#7 unpadding
+	pad := int(data[len(data)-1])
+	if pad == 0 || pad > aes.BlockSize {
+		err = fmt.Errorf("%w: encrypted source url", ErrImgproxyInvalid)
+		return
+	}
+	for _, b := range data[len(data)-pad:] {
+		if int(b) != pad {
+			err = fmt.Errorf("%w: encrypted source url", ErrImgproxyInvalid)
+			return
+		}
+	}
+	source = string(data[:len(data)-pad])
+	return
+}
+
+func parseBase64Source(s string) (source, format string, err error) {
+	s, format = splitImgproxyExtension(s)
+	buf, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		err = fmt.Errorf("%w: base64 source url", ErrImgproxyInvalid)
+		return
+	}
+	source = string(buf)
+	return
+}
+
+func splitImgproxyExtension(s string) (string, string) {
+	if idx := strings.LastIndex(s, "."); idx >= 0 {
+		return s[:idx], s[idx+1:]
+	}
+	return s, ""
+}
+
+func setImgproxyGravity(p *Params, args []string) {
+	p.HAlign, p.VAlign, p.Smart = "", "", false
+	switch args[0] {
+	case "no":
+		p.VAlign = VAlignTop
+	case "so":
+		p.VAlign = VAlignBottom
+	case "ea":
+		p.HAlign = HAlignRight
+	case "we":
+		p.HAlign = HAlignLeft
+	case "noea":
+		p.VAlign, p.HAlign = VAlignTop, HAlignRight
+	case "nowe":
+		p.VAlign, p.HAlign = VAlignTop, HAlignLeft
+	case "soea":
+		p.VAlign, p.HAlign = VAlignBottom, HAlignRight
+	case "sowe":
+		p.VAlign, p.HAlign = VAlignBottom, HAlignLeft
+	case "sm":
+		p.Smart = true
+	case "fp":
+		if len(args) >= 3 {
+			p.Filters = append(p.Filters, Filter{Name: "focal", Args: args[1] + "x" + args[2]})
+		}
+	}
+}
+
+func parseImgproxyColor(args []string) (string, error) {
+	if len(args) == 1 {
+		return strings.TrimPrefix(args[0], "#"), nil
+	}
+	if len(args) != 3 {
+		return "", fmt.Errorf("%w: background %s", ErrImgproxyInvalid, strings.Join(args, ":"))
+	}
+	var hex string
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 || n > 255 {
+			return "", fmt.Errorf("%w: background %s", ErrImgproxyInvalid, strings.Join(args, ":"))
+		}
+		hex += fmt.Sprintf("%02x", n)
+	}
+	return hex, nil
+}
+
+func parseImgproxySize(
+	args []string, width, height int, enlarge, extend bool,
+) (int, int, bool, bool) {
+	if len(args) > 0 && args[0] != "" {
+		width, _ = strconv.Atoi(args[0])
+	}
+	if len(args) > 1 && args[1] != "" {
+		height, _ = strconv.Atoi(args[1])
+	}
+	if len(args) > 2 && args[2] != "" {
+		enlarge = parseImgproxyBool(args[2])
+	}
+	if len(args) > 3 && args[3] != "" {
+		extend = parseImgproxyBool(args[3])
+	}
+	return width, height, enlarge, extend
+}
+
+func parseImgproxyBool(s string) bool {
+	return s == "1" || s == "t" || s == "true"
+}
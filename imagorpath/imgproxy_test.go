@@ -0,0 +1,152 @@
+package imagorpath
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImgproxyParser(t *testing.T) {
+	key, _ := hex.DecodeString("943b421c9eb07c830af81030552c86009268de4e532ba2ee2eab8247c6da0881")
+	salt, _ := hex.DecodeString("520f986b998545b4785e0defbc4f3c1203f22de2374a3d53cb7a7fe9fea309c5")
+	encKey, _ := hex.DecodeString("1eb5b0e971ad7f45324c1bb15c947cb207c43152fa5c6c7f35c4f36e0c18e0f1")
+	parser := &ImgproxyParser{Key: key, Salt: salt, EncryptionKey: encKey}
+
+	p, err := parser.Parse("/m3k5QADfcKPDj-SDI2AIogZbC3FlAXszuwhtWXYqavc/rs:fit:300:300/plain/http://img.example.com/pretty/image.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, Params{
+		Image: "http://img.example.com/pretty/image.jpg",
+		FitIn: true, Width: 300, Height: 300,
+	}, p)
+
+	_, err = parser.Parse("/insecure/rs:fit:300:300/plain/http://img.example.com/pretty/image.jpg")
+	assert.ErrorIs(t, err, ErrImgproxySignatureMismatch)
+
+	encrypt := func(plaintext []byte) string {
+		block, _ := aes.NewCipher(encKey)
+		buf := make([]byte, aes.BlockSize+len(plaintext))
+		copy(buf, "0123456789abcdef")
+		cipher.NewCBCEncrypter(block, buf[:aes.BlockSize]).CryptBlocks(buf[aes.BlockSize:], plaintext)
+		return base64.RawURLEncoding.EncodeToString(buf)
+	}
+	plaintext := []byte("http://img.example.com/pretty/image.jpg")
+	pad := aes.BlockSize - len(plaintext)%aes.BlockSize
+	for i := 0; i < pad; i++ {
+		plaintext = append(plaintext, byte(pad))
+	}
+	encrypted := encrypt(plaintext)
+	// last byte claims 3 bytes of padding, preceded by source bytes
+	badPadding := encrypt([]byte("http://img.example.com/pretty/image.jpg\x09\x09\x09\x09\x09\x09xy\x03"))
+
+	tests := []struct {
+		name   string
+		uri    string
+		params Params
+		err    error
+	}{
+		{
+			name: "fill smart quality format",
+			uri:  "rs:fill:300:200/g:sm/q:80/f:webp/plain/http://img.example.com/pretty/image.jpg",
+			params: Params{
+				Image: "http://img.example.com/pretty/image.jpg",
+				Width: 300, Height: 200, Smart: true,
+				Filters: Filters{
+					{Name: "quality", Args: "80"},
+					{Name: "no_upscale"},
+					{Name: "format", Args: "webp"},
+				},
+			},
+		},
+		{
+			name: "plain escaped extension",
+			uri:  "w:100/h:50/rt:force/dpr:2/plain/http%3A%2F%2Fimg.example.com%2Fimage.jpg@png",
+			params: Params{
+				Image: "http://img.example.com/image.jpg",
+				Width: 200, Height: 100, Stretch: true,
+				Filters: Filters{{Name: "format", Args: "png"}},
+			},
+		},
+		{
+			name: "base64 extend gravity",
+			uri: "s:300:200:1:1/ex:1:noea/bg:255:0:16/rot:90/bl:2/sh:1.5/mb:10000/sm:1/pd:10:20/t:5/" +
+				base64.RawURLEncoding.EncodeToString([]byte("http://img.example.com/pretty/image.jpg"))[:20] + "/" +
+				base64.RawURLEncoding.EncodeToString([]byte("http://img.example.com/pretty/image.jpg"))[20:] + ".jpg",
+			params: Params{
+				Image: "http://img.example.com/pretty/image.jpg",
+				FitIn: true, Width: 300, Height: 200,
+				HAlign: HAlignRight, VAlign: VAlignTop,
+				Trim: true, TrimTolerance: 5,
+				PaddingTop: 10, PaddingRight: 20, PaddingBottom: 10, PaddingLeft: 20,
+				Filters: Filters{
+					{Name: "background_color", Args: "ff0010"},
+					{Name: "rotate", Args: "270"},
+					{Name: "blur", Args: "2"},
+					{Name: "sharpen", Args: "1.5"},
+					{Name: "max_bytes", Args: "10000"},
+					{Name: "strip_metadata"},
+					{Name: "upscale"},
+					{Name: "fill", Args: "ff0010"},
+					{Name: "format", Args: "jpeg"},
+				},
+			},
+		},
+		{
+			name: "encrypted focal point",
+			uri:  "g:fp:0.3:0.6/enc/" + encrypted,
+			params: Params{
+				Image: "http://img.example.com/pretty/image.jpg",
+				FitIn: true,
+				Filters: Filters{
+					{Name: "focal", Args: "0.3x0.6"},
+				},
+			},
+		},
+		{
+			name: "unsupported option",
+			uri:  "pr:foo/plain/http://img.example.com/pretty/image.jpg",
+			err:  ErrImgproxyInvalid,
+		},
+		{
+			name: "unsupported resizing type",
+			uri:  "rt:foo/plain/http://img.example.com/pretty/image.jpg",
+			err:  ErrImgproxyInvalid,
+		},
+		{
+			name: "missing source",
+			uri:  "rs:fit:300:300",
+			err:  ErrImgproxyInvalid,
+		},
+		{
+			name: "invalid encrypted source",
+			uri:  "enc/abcd",
+			err:  ErrImgproxyInvalid,
+		},
+		{
+			name: "invalid encrypted source padding",
+			uri:  "enc/" + badPadding,
+			err:  ErrImgproxyInvalid,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uri := "/" + parser.Sign("/"+test.uri) + "/" + test.uri
+			p, err := parser.Parse(uri)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.params, p)
+		})
+	}
+
+	unsigned := &ImgproxyParser{}
+	p, err = unsigned.Parse("/insecure/w:10/plain/foo.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, Params{Image: "foo.jpg", FitIn: true, Width: 10}, p)
+}
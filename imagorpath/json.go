@@ -0,0 +1,84 @@
+package imagorpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrJSONInvalid malformed JSON params error
+var ErrJSONInvalid = errors.New("json params invalid")
+
+const jsonSignPrefix = "json/"
+
+// ParseJSON parse Params from JSON request body,
+// in the same shape as the /params endpoint output
+func ParseJSON(r io.Reader) (p Params, err error) {
+	if err = json.NewDecoder(r).Decode(&p); err != nil {
+		err = fmt.Errorf("%w: %w", ErrJSONInvalid, err)
+		return
+	}
+	p.Path = ""
+	p.Params = false
+	if p.Image == "" {
+		err = fmt.Errorf("%w: missing image", ErrJSONInvalid)
+	}
+	return
+}
+
+// CanonicalJSON canonical JSON serialization of Params for signing,
+// excluding endpoint attributes path, hash, unsafe and policy.
+//
+// The canonical form is compact JSON without whitespace, where:
+//   - fields of zero value are omitted, so are empty filters
+//   - object keys are sorted in byte order, including filter objects {"args","name"}
+//   - numbers are in the shortest form that round trips, as JavaScript Number.toString,
+//     e.g. 500, 0.1, 1e+21
+//   - strings escape only quote, backslash, control characters as \b, \f, \n, \r, \t
+//     or lowercase \u00xx, and U+2028, U+2029 as \u2028, \u2029. Invalid UTF-8 is replaced by U+FFFD,
+//     other characters including <, > and & are not escaped
+func CanonicalJSON(p Params) string {
+	p.Params = false
+	p.Path = ""
+	p.Hash = ""
+	p.Unsafe = false
+	p.Policy = ""
+	if len(p.Filters) == 0 {
+		p.Filters = nil
+	}
+	buf, _ := json.Marshal(p)
+	// decode to maps and re-encode for sorted keys, keeping number literals
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	_ = dec.Decode(&v)
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	return string(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+}
+
+// SignJSON sign canonical JSON serialization of Params with signer
+func SignJSON(p Params, signer Signer) string {
+	return signer.Sign(jsonSignPrefix + CanonicalJSON(p))
+}
+
+// GenerateJSON generate JSON request body with signature by Params struct with signer.
+// Unsafe if signer is nil
+func GenerateJSON(p Params, signer Signer) []byte {
+	p.Params = false
+	p.Path = ""
+	p.Policy = ""
+	if signer != nil {
+		p.Hash = SignJSON(p, signer)
+		p.Unsafe = false
+	} else {
+		p.Hash = ""
+		p.Unsafe = true
+	}
+	buf, _ := json.Marshal(p)
+	return buf
+}
@@ -0,0 +1,69 @@
+package imagorpath
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	signer := NewDefaultSigner("mysecret")
+	p := Params{
+		Image:  "raw.githubusercontent.com/cshum/imagor/master/testdata/gopher.png",
+		FitIn:  true,
+		Width:  500,
+		Height: 400,
+		Filters: Filters{
+			{Name: "fill", Args: "white"},
+		},
+	}
+	buf := GenerateJSON(p, signer)
+	parsed, err := ParseJSON(strings.NewReader(string(buf)))
+	assert.NoError(t, err)
+	assert.Equal(t, SignJSON(p, signer), parsed.Hash)
+	assert.False(t, parsed.Unsafe)
+	assert.Equal(t, SignJSON(p, signer), SignJSON(parsed, signer))
+
+	// endpoint attributes excluded from signature
+	p2 := p
+	p2.Path = "foo"
+	p2.Hash = "bar"
+	p2.Unsafe = true
+	p2.Policy = "baz"
+	p2.Filters = Filters{}
+	p.Filters = nil
+	assert.Equal(t, CanonicalJSON(p), CanonicalJSON(p2))
+	assert.Equal(t, `{"fit_in":true,"height":400,"image":"raw.githubusercontent.com/cshum/imagor/master/testdata/gopher.png","width":500}`, CanonicalJSON(p2))
+
+	// sorted keys, number formatting and string escaping
+	p3 := Params{
+		Image:     "a&b<c>/\u00e9\u2028\t.png",
+		CropLeft:  0.1,
+		CropRight: 1e21,
+		Width:     10,
+		Smart:     true,
+		Filters:   Filters{{Name: "fill", Args: "white"}, {Name: "quality", Args: "80"}},
+	}
+	assert.Equal(t, `{"crop_left":0.1,"crop_right":1e+21,"filters":[{"args":"white","name":"fill"},{"args":"80","name":"quality"}],"image":"a&b<c>/é\u2028\t.png","smart":true,"width":10}`, CanonicalJSON(p3))
+	assert.Equal(t, "mjS82_oIfbXfnieO8Zbk5tAe4WA=", SignJSON(p3, signer))
+
+	unsafe, err := ParseJSON(strings.NewReader(string(GenerateJSON(p, nil))))
+	assert.NoError(t, err)
+	assert.True(t, unsafe.Unsafe)
+	assert.Empty(t, unsafe.Hash)
+
+	// params endpoint output shape
+	res, _ := json.Marshal(Parse("params/unsafe/fit-in/500x400/filters:fill(white)/gopher.png"))
+	parsed, err = ParseJSON(strings.NewReader(string(res)))
+	assert.NoError(t, err)
+	assert.Empty(t, parsed.Path)
+	assert.Equal(t, "fit-in/500x400/filters:fill(white)/gopher.png", GeneratePath(parsed))
+
+	_, err = ParseJSON(strings.NewReader("{"))
+	assert.True(t, errors.Is(err, ErrJSONInvalid))
+	_, err = ParseJSON(strings.NewReader(`{"width":100}`))
+	assert.True(t, errors.Is(err, ErrJSONInvalid))
+}
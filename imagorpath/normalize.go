@@ -2,6 +2,8 @@ package imagorpath
 
 import (
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -141,3 +143,189 @@ func Normalize(image string, safeChars SafeChars) string {
 	}
 	return escape(image, safeChars.ShouldEscape)
 }
+
+// settingFilters filters that configure the pipeline instead of applying operations,
+// so that their order does not matter. Filters of the same group are deduplicated, where the last one wins,
+// except max_frames where the minimum wins. Values ignored by the processor do not override
+var settingFilters = map[string]string{
+	"format":         "format",
+	"quality":        "quality",
+	"max_bytes":      "max_bytes",
+	"target_quality": "target_quality",
+	"max_frames":     "max_frames",
+	"page":           "page",
+	"dpi":            "dpi",
+	"orient":         "orient",
+	"bitdepth":       "bitdepth",
+	"compression":    "compression",
+	"autojpg":        "autojpg",
+	"palette":        "palette",
+	"lossless":       "lossless",
+	"near_lossless":  "near_lossless",
+	"effort":         "effort",
+	"subsampling":    "subsampling",
+	"progressive":    "progressive",
+	"trellis":        "trellis",
+	"dither":         "dither",
+	"strip_exif":     "strip_exif",
+	"strip_metadata": "strip_metadata",
+	"preview":        "preview",
+	"raw":            "raw",
+	"upscale":        "upscale",
+	"no_upscale":     "upscale",
+	"stretch":        "stretch",
+}
+
+// extFormats output format by image extension, for image exported in the same format
+var extFormats = map[string]string{
+	".jpg":  "jpeg",
+	".jpeg": "jpeg",
+	".png":  "png",
+	".gif":  "gif",
+	".webp": "webp",
+	".avif": "avif",
+	".heif": "heif",
+	".heic": "heif",
+	".tif":  "tiff",
+	".tiff": "tiff",
+	".jp2":  "jp2",
+	".jxl":  "jxl",
+}
+
+// DefaultQualityFunc returns the default export quality of the output format, 0 if unknown
+type DefaultQualityFunc func(format string) int
+
+// Canonicalize Params using DefaultFilterSchema, without default quality
+func Canonicalize(p Params) Params {
+	return DefaultFilterSchema.Canonicalize(p, nil)
+}
+
+// Canonicalize Params to the canonical form, such that Params producing
+// identical results share the same generated Path:
+// filter arguments normalized by the schema, order independent filters deduplicated and sorted,
+// and default values removed.
+// Quality equals to the defaultQuality of the output format is removed,
+// with the format resolved from the image extension if not specified by filter
+func (s FilterSchema) Canonicalize(p Params, defaultQuality DefaultQualityFunc) Params {
+	if p.Width < 0 {
+		p.Width = -p.Width
+		p.HFlip = !p.HFlip
+	}
+	if p.Height < 0 {
+		p.Height = -p.Height
+		p.VFlip = !p.VFlip
+	}
+	if p.Trim || p.TrimBy == TrimByTopLeft || p.TrimBy == TrimByBottomRight {
+		p.Trim = true
+		if p.TrimBy != TrimByBottomRight {
+			p.TrimBy = TrimByTopLeft
+		}
+		if p.TrimTolerance == 1 {
+			p.TrimTolerance = 0 // tolerance 0 defaults to 1
+		}
+	} else {
+		p.TrimBy = ""
+		p.TrimTolerance = 0
+	}
+	if p.HAlign != HAlignLeft && p.HAlign != HAlignRight {
+		p.HAlign = ""
+	}
+	if p.VAlign != VAlignTop && p.VAlign != VAlignBottom {
+		p.VAlign = ""
+	}
+	var (
+		ops      Filters
+		settings = map[string]Filter{}
+		focals   = map[string]Filter{}
+	)
+	for _, f := range p.Filters {
+		if tf, err := s.Validate(f); err == nil {
+			f = tf.Filter
+		}
+		if f.Name == "format" && f.Args == "jpg" {
+			f.Args = "jpeg"
+		}
+		if f.Name == "focal" {
+			focals[f.Args] = f
+		} else if group, ok := settingFilters[f.Name]; ok {
+			if ignoredSetting(f) {
+				continue
+			}
+			if prev, ok := settings[group]; ok && f.Name == "max_frames" {
+				// processor takes the minimum of max_frames
+				if atoi(f.Args) >= atoi(prev.Args) {
+					continue
+				}
+			}
+			settings[group] = f
+		} else {
+			ops = append(ops, f)
+		}
+	}
+	if _, ok := settings["stretch"]; ok {
+		p.Stretch = true
+		delete(settings, "stretch")
+	}
+	if f, ok := settings["upscale"]; ok && (f.Name == "upscale") == !p.FitIn {
+		// upscale by default unless fit-in
+		delete(settings, "upscale")
+	}
+	// quality is kept as the upper bound of target_quality and max_bytes searches
+	_, hasTargetQuality := settings["target_quality"]
+	_, hasMaxBytes := settings["max_bytes"]
+	if f, ok := settings["quality"]; ok && defaultQuality != nil && !hasTargetQuality && !hasMaxBytes {
+		var format string
+		if _, ok := settings["autojpg"]; ok {
+			format = "jpeg"
+		} else if f, ok := settings["format"]; ok {
+			format = f.Args
+		} else {
+			format = extFormats[strings.ToLower(path.Ext(p.Image))]
+		}
+		if q := defaultQuality(format); format != "" && q > 0 && strconv.Itoa(q) == f.Args {
+			delete(settings, "quality")
+		}
+	}
+	if f, ok := settings["page"]; ok && f.Args == "1" {
+		delete(settings, "page")
+	}
+	var sorted Filters
+	for _, f := range settings {
+		sorted = append(sorted, f)
+	}
+	for _, f := range focals {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Name == sorted[j].Name {
+			return sorted[i].Args < sorted[j].Args
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	p.Filters = append(ops, sorted...)
+	if len(p.Filters) == 0 {
+		p.Filters = nil
+	}
+	p.Path = GeneratePath(p)
+	return p
+}
+
+// ignoredSetting returns true if value of the setting filter is ignored by the processor
+func ignoredSetting(f Filter) bool {
+	switch f.Name {
+	case "max_frames", "page", "dpi", "orient", "max_bytes":
+		return atoi(f.Args) <= 0
+	case "target_quality":
+		n, err := strconv.ParseFloat(f.Args, 64)
+		return err != nil || n <= 0 || n > 1
+	case "dither":
+		n, err := strconv.ParseFloat(f.Args, 64)
+		return err != nil || n < 0 || n > 1
+	}
+	return false
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -25,6 +25,7 @@ type Params struct {
 	Image         string  `json:"image,omitempty"`
 	Unsafe        bool    `json:"unsafe,omitempty"`
 	Hash          string  `json:"hash,omitempty"`
+	Policy        string  `json:"policy,omitempty"`
 	Meta          bool    `json:"meta,omitempty"`
 	Trim          bool    `json:"trim,omitempty"`
 	TrimBy        string  `json:"trim_by,omitempty"`
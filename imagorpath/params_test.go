@@ -460,3 +460,137 @@ func TestParseFilters(t *testing.T) {
 	}, filters)
 	assert.Empty(t, img)
 }
+
+func testDefaultQuality(format string) int {
+	switch format {
+	case "jpeg":
+		return 80
+	case "webp":
+		return 75
+	}
+	return 0
+}
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		uris     []string
+		expected string
+	}{
+		{
+			name: "dimensions",
+			uris: []string{
+				"fit-in/300x0/foo.jpg",
+				"fit-in/300x/foo.jpg",
+				"fit-in/300x0/center/middle/foo.jpg",
+			},
+			expected: "fit-in/300x0/foo.jpg",
+		},
+		{
+			name: "filters order and defaults",
+			uris: []string{
+				"fit-in/300x200/filters:format(webp):grayscale():quality(75):no_upscale()/foo.jpg",
+				"fit-in/300x200/filters:grayscale():format(webp)/foo.jpg",
+				"fit-in/300x200/filters:format(png):grayscale():format(webp):upscale():no_upscale()/foo.jpg",
+			},
+			expected: "fit-in/300x200/filters:grayscale():format(webp)/foo.jpg",
+		},
+		{
+			name: "numbers normalized",
+			uris: []string{
+				"300x200/filters:quality(070):blur(5.0):format(jpg)/foo.jpg",
+				"300x200/filters:blur(5):quality(70):format(jpeg)/foo.jpg",
+				"300x200/filters:blur(5):format(jpg):quality(70):page(1)/foo.jpg",
+			},
+			expected: "300x200/filters:blur(5):format(jpeg):quality(70)/foo.jpg",
+		},
+		{
+			name: "operations order preserved",
+			uris: []string{
+				"filters:grayscale():fill(FFF):strip_exif()/foo.jpg",
+			},
+			expected: "filters:grayscale():fill(fff):strip_exif()/foo.jpg",
+		},
+		{
+			name: "trim and flip",
+			uris: []string{
+				"trim:1/-300x0/filters:stretch()/foo.jpg",
+				"trim:top-left/stretch/-300x0/foo.jpg",
+			},
+			expected: "trim/stretch/-300x0/foo.jpg",
+		},
+		{
+			name: "default quality of image extension",
+			uris: []string{
+				"300x200/filters:quality(80)/foo.jpg",
+				"300x200/foo.jpg",
+			},
+			expected: "300x200/foo.jpg",
+		},
+		{
+			name: "default quality of autojpg",
+			uris: []string{
+				"300x200/filters:autojpg():quality(80)/foo.png",
+				"300x200/filters:autojpg()/foo.png",
+			},
+			expected: "300x200/filters:autojpg()/foo.png",
+		},
+		{
+			name: "quality of unknown format",
+			uris: []string{
+				"300x200/filters:quality(80)/foo",
+			},
+			expected: "300x200/filters:quality(80)/foo",
+		},
+		{
+			name: "quality of format not default",
+			uris: []string{
+				"300x200/filters:quality(80):format(webp)/foo.jpg",
+				"300x200/filters:format(webp):quality(75):quality(80)/foo.jpg",
+			},
+			expected: "300x200/filters:format(webp):quality(80)/foo.jpg",
+		},
+		{
+			name: "quality upper bound of target_quality",
+			uris: []string{
+				"300x200/filters:target_quality(0.95):quality(80)/foo.jpg",
+			},
+			expected: "300x200/filters:quality(80):target_quality(0.95)/foo.jpg",
+		},
+		{
+			name: "max_frames minimum",
+			uris: []string{
+				"filters:max_frames(3):max_frames(5)/foo.gif",
+				"filters:max_frames(5):max_frames(3)/foo.gif",
+				"filters:max_frames(3):max_frames(0)/foo.gif",
+			},
+			expected: "filters:max_frames(3)/foo.gif",
+		},
+		{
+			name: "ignored settings",
+			uris: []string{
+				"filters:page(2):page(0):orient(90):orient(0)/foo.pdf",
+				"filters:orient(90):page(2)/foo.pdf",
+			},
+			expected: "filters:orient(90):page(2)/foo.pdf",
+		},
+		{
+			name: "focal",
+			uris: []string{
+				"filters:focal(0.1x0.1:0.5x0.5):focal(0.2,0.3):upscale()/foo.jpg",
+				"filters:focal(0.2,0.3):focal(0.1x0.1:0.5x0.5)/foo.jpg",
+			},
+			expected: "filters:focal(0.1x0.1:0.5x0.5):focal(0.2,0.3)/foo.jpg",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, uri := range test.uris {
+				p := DefaultFilterSchema.Canonicalize(Parse(uri), testDefaultQuality)
+				assert.Equal(t, test.expected, p.Path, uri)
+				assert.Equal(t, test.expected, GeneratePath(
+					DefaultFilterSchema.Canonicalize(Parse(p.Path), testDefaultQuality)), "idempotent")
+			}
+		})
+	}
+}
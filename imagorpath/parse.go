@@ -12,7 +12,7 @@ var pathRegex = regexp.MustCompile(
 		// params
 		"(params/)?" +
 		// hash
-		"((unsafe/)|([A-Za-z0-9-_=]{8,})/)?" +
+		"((unsafe/)|(policy/([A-Za-z0-9-_=]+\\.[A-Za-z0-9-_=]+)/)|([A-Za-z0-9-_=]{8,})/)?" +
 		// path
 		"(.+)?",
 )
@@ -54,7 +54,7 @@ func Parse(path string) Params {
 // Apply Params struct from imagor endpoint URI on top of existing Params
 func Apply(p Params, path string) Params {
 	match := pathRegex.FindStringSubmatch(breaksCleaner.Replace(path))
-	if len(match) < 6 {
+	if len(match) < 8 {
 		return p
 	}
 	index := 1
@@ -64,10 +64,12 @@ func Apply(p Params, path string) Params {
 	index++
 	if match[index+1] == "unsafe/" {
 		p.Unsafe = true
-	} else if len(match[index+2]) > 8 {
-		p.Hash = match[index+2]
+	} else if match[index+2] != "" {
+		p.Policy = match[index+3]
+	} else if len(match[index+4]) > 8 {
+		p.Hash = match[index+4]
 	}
-	index += 3
+	index += 5
 	p.Path = match[index]
 
 	match = paramsRegex.FindStringSubmatch(p.Path)
@@ -0,0 +1,179 @@
+package imagorpath
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPolicyInvalid malformed policy token error
+	ErrPolicyInvalid = errors.New("policy invalid")
+	// ErrPolicySignatureMismatch policy token signature mismatch error
+	ErrPolicySignatureMismatch = errors.New("policy signature mismatch")
+	// ErrPolicyExpired policy expired error
+	ErrPolicyExpired = errors.New("policy expired")
+	// ErrPolicyViolation params not within policy constraints error
+	ErrPolicyViolation = errors.New("policy violation")
+)
+
+// Policy signed URL constraints, allowing the client to choose params within the bounds.
+// Zero value fields are not constrained
+type Policy struct {
+	// Prefix allowed image prefix
+	Prefix string `json:"prefix,omitempty"`
+	// MaxWidth maximum absolute width. Width must be explicitly set if specified
+	MaxWidth int `json:"max_width,omitempty"`
+	// MaxHeight maximum absolute height. Height must be explicitly set if specified
+	MaxHeight int `json:"max_height,omitempty"`
+	// Filters allowed filter names
+	Filters []string `json:"filters,omitempty"`
+	// Formats allowed format() filter arguments
+	Formats []string `json:"formats,omitempty"`
+	// Expire expiry unix timestamp in milliseconds
+	Expire int64 `json:"expire,omitempty"`
+	// ClientIP allowed client IP address
+	ClientIP string `json:"client_ip,omitempty"`
+}
+
+// GeneratePolicy generate signed policy token by Policy struct with signer
+func GeneratePolicy(policy Policy, signer Signer) string {
+	buf, _ := json.Marshal(policy)
+	payload := base64.RawURLEncoding.EncodeToString(buf)
+	return payload + "." + signer.Sign(policySignPrefix+payload)
+}
+
+// GenerateWithPolicy generate imagor endpoint signed by policy token by Params struct
+func GenerateWithPolicy(p Params, token string) string {
+	return "policy/" + token + "/" + GeneratePath(p)
+}
+
+// ParsePolicy parse and verify signed policy token with signer
+func ParsePolicy(token string, signer Signer) (policy Policy, err error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || payload == "" || sig == "" {
+		err = ErrPolicyInvalid
+		return
+	}
+	if signer == nil || signer.Sign(policySignPrefix+payload) != sig {
+		err = ErrPolicySignatureMismatch
+		return
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		err = ErrPolicyInvalid
+		return
+	}
+	if err = json.Unmarshal(buf, &policy); err != nil {
+		err = ErrPolicyInvalid
+		return
+	}
+	return
+}
+
+// Verify checks if Params and client IP are within the policy constraints
+func (policy Policy) Verify(p Params, clientIP string) error {
+	if policy.Expire > 0 && time.Now().After(time.UnixMilli(policy.Expire)) {
+		return ErrPolicyExpired
+	}
+	if policy.ClientIP != "" && policy.ClientIP != clientIP {
+		return fmt.Errorf("%w: client ip not allowed", ErrPolicyViolation)
+	}
+	if !policy.AllowImage(p.Image) {
+		return fmt.Errorf("%w: image not allowed", ErrPolicyViolation)
+	}
+	if policy.MaxWidth > 0 && (p.Width == 0 || abs(p.Width) > policy.MaxWidth) {
+		return fmt.Errorf("%w: width exceeded", ErrPolicyViolation)
+	}
+	if policy.MaxHeight > 0 && (p.Height == 0 || abs(p.Height) > policy.MaxHeight) {
+		return fmt.Errorf("%w: height exceeded", ErrPolicyViolation)
+	}
+	for _, f := range p.Filters {
+		if len(policy.Filters) > 0 && !contains(policy.Filters, f.Name) {
+			return fmt.Errorf("%w: filter %s not allowed", ErrPolicyViolation, f.Name)
+		}
+		if f.Name == "format" && !policy.AllowFormat(f.Args) {
+			return fmt.Errorf("%w: format %s not allowed", ErrPolicyViolation, f.Args)
+		}
+		for _, image := range filterImages(f) {
+			if !policy.AllowImage(image) {
+				return fmt.Errorf("%w: filter %s image not allowed", ErrPolicyViolation, f.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// AllowImage checks if image is within the policy prefix, without parent directory segments
+func (policy Policy) AllowImage(image string) bool {
+	if policy.Prefix == "" {
+		return true
+	}
+	if !strings.HasPrefix(image, policy.Prefix) {
+		return false
+	}
+	for _, seg := range strings.Split(image, "/") {
+		if seg == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// maskShapes built-in shapes of mask filter that do not load image
+var maskShapes = []string{"circle", "ellipse", "hexagon"}
+
+// filterImages returns unescaped images loaded by the filter arguments
+func filterImages(f Filter) (images []string) {
+	args := strings.Split(f.Args, ",")
+	switch f.Name {
+	case "watermark", "layer":
+		images = append(images, unescape(args[0]))
+	case "mask":
+		if image := unescape(args[0]); !contains(maskShapes, image) && !strings.HasPrefix(image, "path:") {
+			images = append(images, image)
+		}
+	case "text":
+		// text options following text,x,y,size,color,alpha,font
+		for i := 7; i < len(args); i++ {
+			if parts := strings.Split(args[i], ":"); parts[0] == "fontfile" && len(parts) > 1 {
+				images = append(images, unescape(parts[1]))
+			}
+		}
+	}
+	return
+}
+
+func unescape(s string) string {
+	if u, err := url.QueryUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// AllowFormat checks if format is allowed by the policy
+func (policy Policy) AllowFormat(format string) bool {
+	return len(policy.Formats) == 0 || contains(policy.Formats, format)
+}
+
+const policySignPrefix = "policy/"
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,96 @@
+package imagorpath
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	signer := NewDefaultSigner("1234")
+	policy := Policy{
+		Prefix:    "users/",
+		MaxWidth:  500,
+		MaxHeight: 500,
+		Filters:   []string{"format", "quality"},
+		Formats:   []string{"webp", "jpeg"},
+		ClientIP:  "10.0.0.1",
+	}
+	token := GeneratePolicy(policy, signer)
+
+	parsed, err := ParsePolicy(token, signer)
+	assert.NoError(t, err)
+	assert.Equal(t, policy, parsed)
+
+	_, err = ParsePolicy(token, NewDefaultSigner("abcd"))
+	assert.ErrorIs(t, err, ErrPolicySignatureMismatch)
+	_, err = ParsePolicy(token, nil)
+	assert.ErrorIs(t, err, ErrPolicySignatureMismatch)
+	_, err = ParsePolicy("foobar", signer)
+	assert.ErrorIs(t, err, ErrPolicyInvalid)
+	// path signature of the payload is not a valid policy signature
+	payload := token[:strings.Index(token, ".")]
+	_, err = ParsePolicy(payload+"."+signer.Sign(payload), signer)
+	assert.ErrorIs(t, err, ErrPolicySignatureMismatch)
+
+	uri := GenerateWithPolicy(Params{
+		Width: 300, Height: -200, Image: "users/a.jpg",
+		Filters: Filters{{Name: "format", Args: "webp"}},
+	}, token)
+	assert.Equal(t, "policy/"+token+"/300x-200/filters:format(webp)/users/a.jpg", uri)
+	p := Parse(uri)
+	assert.Equal(t, token, p.Policy)
+	assert.Empty(t, p.Hash)
+	assert.False(t, p.Unsafe)
+	assert.Equal(t, "300x-200/filters:format(webp)/users/a.jpg", p.Path)
+	assert.NoError(t, parsed.Verify(p, "10.0.0.1"))
+
+	tests := []struct {
+		name string
+		uri  string
+		ip   string
+	}{
+		{name: "client ip", uri: "300x200/users/a.jpg", ip: "10.0.0.2"},
+		{name: "prefix", uri: "300x200/admin/a.jpg"},
+		{name: "traversal", uri: "300x200/users/../admin/a.jpg"},
+		{name: "width", uri: "501x200/users/a.jpg"},
+		{name: "negative width", uri: "-501x200/users/a.jpg"},
+		{name: "height", uri: "300x0/users/a.jpg"},
+		{name: "filter", uri: "300x200/filters:blur(5)/users/a.jpg"},
+		{name: "format", uri: "300x200/filters:format(png)/users/a.jpg"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ip := test.ip
+			if ip == "" {
+				ip = "10.0.0.1"
+			}
+			err := parsed.Verify(Parse(test.uri), ip)
+			assert.True(t, errors.Is(err, ErrPolicyViolation), "%v", err)
+		})
+	}
+
+	prefixed := Policy{Prefix: "users/"}
+	for _, uri := range []string{
+		"filters:watermark(users/w.png):layer(users%2Fw.png,center,center):mask(circle):mask(path%3AM0%200%20L100%20100Z)/users/a.jpg",
+		"filters:text(hello,0,0,20,red,0,sans,width:80p,fontfile:users%2Ffont.ttf):mask(users/m.png,invert)/users/a.jpg",
+	} {
+		assert.NoError(t, prefixed.Verify(Parse(uri), ""), uri)
+	}
+	for _, uri := range []string{
+		"filters:watermark(admin/w.png)/users/a.jpg",
+		"filters:watermark(users%2F..%2Fadmin%2Fw.png)/users/a.jpg",
+		"filters:layer(https%3A%2F%2Fexample.com%2Fw.png,center,center)/users/a.jpg",
+		"filters:mask(admin%2Fm.png)/users/a.jpg",
+		"filters:text(hello,0,0,20,red,0,sans,fontfile:admin%2Ffont.ttf)/users/a.jpg",
+	} {
+		err := prefixed.Verify(Parse(uri), "")
+		assert.True(t, errors.Is(err, ErrPolicyViolation), "%s %v", uri, err)
+	}
+
+	expired := Policy{Expire: time.Now().Add(-time.Second).UnixMilli()}
+	assert.ErrorIs(t, expired.Verify(Params{}, ""), ErrPolicyExpired)
+}
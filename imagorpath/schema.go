@@ -0,0 +1,610 @@
+package imagorpath
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownFilter unknown filter name error
+var ErrUnknownFilter = errors.New("unknown filter")
+
+// ArgType filter argument type
+type ArgType string
+
+const (
+	// ArgString string argument
+	ArgString ArgType = "string"
+	// ArgInt integer argument
+	ArgInt ArgType = "int"
+	// ArgFloat floating point argument
+	ArgFloat ArgType = "float"
+	// ArgBool boolean argument
+	ArgBool ArgType = "bool"
+	// ArgColor color name or hexadecimal rgb expression argument
+	ArgColor ArgType = "color"
+	// ArgPosition position argument, number of pixels, percentage e.g. 20p, ratio e.g. 0.2, or keyword
+	ArgPosition ArgType = "position"
+	// ArgRegion region argument, either a single AxB:CxD expression
+	// or four numeric arguments left,top,right,bottom, in pixels or ratios
+	ArgRegion ArgType = "region"
+	// ArgMatrix matrix argument, numbers in row-major order separated by ;
+	ArgMatrix ArgType = "matrix"
+)
+
+// regionArgs number of arguments of region in left,top,right,bottom form
+const regionArgs = 4
+
+// MaxConvolutionSize maximum number of rows and columns of convolution matrix
+const MaxConvolutionSize = 15
+
+// ArgSpec filter argument schema
+type ArgSpec struct {
+	Name string  `json:"name"`
+	Type ArgType `json:"type"`
+	// Optional argument can be omitted
+	Optional bool `json:"optional,omitempty"`
+	// Variadic last argument can be repeated
+	Variadic bool `json:"variadic,omitempty"`
+	// Min inclusive minimum of numeric argument
+	Min *float64 `json:"min,omitempty"`
+	// Max inclusive maximum of numeric argument, or number of matrix items
+	Max *float64 `json:"max,omitempty"`
+	// Keywords allowed values for string argument,
+	// or keywords accepted in addition to values of other types
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// FilterSpec filter schema with argument specs
+type FilterSpec struct {
+	Name string    `json:"name"`
+	Args []ArgSpec `json:"args,omitempty"`
+}
+
+// FilterSchema filter specs by filter name
+type FilterSchema map[string]FilterSpec
+
+// TypedFilter Filter with normalized Args and typed arguments
+type TypedFilter struct {
+	Filter
+	TypedArgs []interface{} `json:"typed_args,omitempty"`
+}
+
+// FilterError invalid filter error, naming the filter and argument
+type FilterError struct {
+	Filter string
+	Arg    string
+	Reason string
+}
+
+// Error implements error
+func (e *FilterError) Error() string {
+	if e.Arg == "" {
+		return fmt.Sprintf("invalid filter %s: %s", e.Filter, e.Reason)
+	}
+	return fmt.Sprintf("invalid filter %s: argument %s %s", e.Filter, e.Arg, e.Reason)
+}
+
+// NewFilterSchema creates FilterSchema from filter specs
+func NewFilterSchema(specs ...FilterSpec) FilterSchema {
+	s := FilterSchema{}
+	for _, spec := range specs {
+		s[spec.Name] = spec
+	}
+	return s
+}
+
+// With returns a copy of FilterSchema with additional filter specs
+func (s FilterSchema) With(specs ...FilterSpec) FilterSchema {
+	ns := make(FilterSchema, len(s)+len(specs))
+	for name, spec := range s {
+		ns[name] = spec
+	}
+	for _, spec := range specs {
+		ns[spec.Name] = spec
+	}
+	return ns
+}
+
+// Validate validates Filter against the schema,
+// returns TypedFilter with normalized Args and typed arguments
+func (s FilterSchema) Validate(f Filter) (TypedFilter, error) {
+	spec, ok := s[f.Name]
+	if !ok {
+		return TypedFilter{Filter: f}, &FilterError{Filter: f.Name, Reason: ErrUnknownFilter.Error()}
+	}
+	return spec.Validate(f)
+}
+
+// ValidateFilters validates Filters against the schema,
+// returns Filters with normalized Args
+func (s FilterSchema) ValidateFilters(filters Filters) (Filters, error) {
+	var res = make(Filters, len(filters))
+	for i, f := range filters {
+		tf, err := s.Validate(f)
+		if err != nil {
+			return filters, err
+		}
+		res[i] = tf.Filter
+	}
+	return res, nil
+}
+
+// Validate validates Filter against the spec,
+// returns TypedFilter with normalized Args and typed arguments
+func (spec FilterSpec) Validate(f Filter) (TypedFilter, error) {
+	var args []string
+	if strings.TrimSpace(f.Args) != "" {
+		args = strings.Split(f.Args, ",")
+	}
+	if n := spec.maxArgs(args); len(args) > n && (n == 0 || !spec.Args[len(spec.Args)-1].Variadic) {
+		return TypedFilter{Filter: f}, &FilterError{
+			Filter: f.Name, Reason: fmt.Sprintf("expects at most %d arguments", n)}
+	}
+	var typed = make([]interface{}, len(args))
+	var normalized = make([]string, len(args))
+	var i int // index of args
+	for _, argSpec := range spec.Args {
+		if i >= len(args) {
+			if !argSpec.Optional && !argSpec.Variadic {
+				return TypedFilter{Filter: f}, &FilterError{
+					Filter: f.Name, Arg: argSpec.Name, Reason: "is required"}
+			}
+			break
+		}
+		last := i + 1
+		if argSpec.Variadic {
+			last = len(args)
+		} else if argSpec.Type == ArgRegion && !isRegionExpr(args[i]) {
+			if last = i + regionArgs; last > len(args) {
+				return TypedFilter{Filter: f}, &FilterError{
+					Filter: f.Name, Arg: argSpec.Name, Reason: "expects AxB:CxD or left,top,right,bottom"}
+			}
+		}
+		for j := i; j < last; j++ {
+			val, str, err := argSpec.parse(strings.TrimSpace(args[j]))
+			if err != nil {
+				return TypedFilter{Filter: f}, &FilterError{
+					Filter: f.Name, Arg: argSpec.Name, Reason: err.Error()}
+			}
+			typed[j] = val
+			normalized[j] = str
+		}
+		i = last
+	}
+	tf := TypedFilter{Filter: Filter{Name: f.Name, Args: strings.Join(normalized, ",")}}
+	if len(typed) > 0 {
+		tf.TypedArgs = typed
+	}
+	return tf, nil
+}
+
+// maxArgs maximum number of args of the spec, with region in the form of args
+func (spec FilterSpec) maxArgs(args []string) (n int) {
+	for _, argSpec := range spec.Args {
+		if argSpec.Type == ArgRegion && (n >= len(args) || !isRegionExpr(args[n])) {
+			n += regionArgs
+		} else {
+			n++
+		}
+	}
+	return
+}
+
+var (
+	hexColorRegex  = regexp.MustCompile("^(?:[0-9a-f]{3}|[0-9a-f]{6}|[0-9a-f]{8})$")
+	nameColorRegex = regexp.MustCompile("^[a-z]+$")
+	regionRegex    = regexp.MustCompile(`^(\d+(?:\.\d+)?)x(\d+(?:\.\d+)?):(\d+(?:\.\d+)?)x(\d+(?:\.\d+)?)$`)
+)
+
+func isRegionExpr(s string) bool {
+	return strings.Contains(s, ":")
+}
+
+func (a ArgSpec) parse(s string) (val interface{}, str string, err error) {
+	if s == "" && a.Optional {
+		return nil, "", nil
+	}
+	for _, k := range a.Keywords {
+		if s == k {
+			return s, s, nil
+		}
+	}
+	switch a.Type {
+	case ArgInt:
+		n, e := strconv.Atoi(s)
+		if e != nil {
+			return nil, "", a.expects("an integer")
+		}
+		if err = a.checkRange(float64(n)); err != nil {
+			return
+		}
+		return n, strconv.Itoa(n), nil
+	case ArgFloat:
+		n, e := strconv.ParseFloat(s, 64)
+		if e != nil {
+			return nil, "", a.expects("a number")
+		}
+		if err = a.checkRange(n); err != nil {
+			return
+		}
+		return n, strconv.FormatFloat(n, 'f', -1, 64), nil
+	case ArgBool:
+		b, e := strconv.ParseBool(s)
+		if e != nil {
+			return nil, "", a.expects("a boolean")
+		}
+		return b, strconv.FormatBool(b), nil
+	case ArgColor:
+		s = strings.ToLower(s)
+		if !hexColorRegex.MatchString(s) && !nameColorRegex.MatchString(s) {
+			return nil, "", a.expects("a color name or hexadecimal rgb expression")
+		}
+		return s, s, nil
+	case ArgPosition:
+		if n, e := strconv.Atoi(s); e == nil {
+			return n, strconv.Itoa(n), nil
+		}
+		if n, e := strconv.ParseFloat(s, 64); e == nil && n > -1 && n < 1 {
+			str = strconv.FormatFloat(n, 'f', -1, 64)
+			if n == 0 {
+				return 0, "0", nil
+			}
+			return n, str, nil
+		}
+		if strings.HasSuffix(s, "p") {
+			if n, e := strconv.Atoi(strings.TrimSuffix(s, "p")); e == nil {
+				str = strconv.Itoa(n) + "p"
+				return str, str, nil
+			}
+		}
+		return nil, "", a.expects("a position")
+	case ArgRegion:
+		if isRegionExpr(s) {
+			m := regionRegex.FindStringSubmatch(s)
+			if m == nil {
+				return nil, "", a.expects("AxB:CxD")
+			}
+			var v [4]string
+			for i := range v {
+				n, _ := strconv.ParseFloat(m[i+1], 64)
+				v[i] = strconv.FormatFloat(n, 'f', -1, 64)
+			}
+			str = v[0] + "x" + v[1] + ":" + v[2] + "x" + v[3]
+			return str, str, nil
+		}
+		n, e := strconv.ParseFloat(s, 64)
+		if e != nil {
+			return nil, "", a.expects("a number")
+		}
+		if err = a.checkRange(n); err != nil {
+			return
+		}
+		return n, strconv.FormatFloat(n, 'f', -1, 64), nil
+	case ArgMatrix:
+		items := strings.Split(s, ";")
+		if a.Max != nil && float64(len(items)) > *a.Max {
+			return nil, "", fmt.Errorf("must have at most %s items", strconv.FormatFloat(*a.Max, 'f', -1, 64))
+		}
+		var matrix = make([]float64, len(items))
+		for i, item := range items {
+			n, e := strconv.ParseFloat(strings.TrimSpace(item), 64)
+			if e != nil {
+				return nil, "", a.expects("numbers separated by ;")
+			}
+			matrix[i] = n
+			items[i] = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		return matrix, strings.Join(items, ";"), nil
+	default:
+		if len(a.Keywords) > 0 {
+			return nil, "", a.expects("one of " + strings.Join(a.Keywords, ", "))
+		}
+		return s, s, nil
+	}
+}
+
+func (a ArgSpec) checkRange(n float64) error {
+	if a.Min != nil && n < *a.Min {
+		return fmt.Errorf("must be at least %s", strconv.FormatFloat(*a.Min, 'f', -1, 64))
+	}
+	if a.Max != nil && n > *a.Max {
+		return fmt.Errorf("must be at most %s", strconv.FormatFloat(*a.Max, 'f', -1, 64))
+	}
+	return nil
+}
+
+func (a ArgSpec) expects(s string) error {
+	if len(a.Keywords) > 0 && a.Type != ArgString {
+		s += " or one of " + strings.Join(a.Keywords, ", ")
+	}
+	return errors.New("expects " + s)
+}
+
+func num(n float64) *float64 {
+	return &n
+}
+
+// DefaultFilterSchema schema of imagor built-in filters
+var DefaultFilterSchema = NewFilterSchema(
+	// utility filters
+	FilterSpec{Name: "attachment", Args: []ArgSpec{
+		{Name: "filename", Type: ArgString, Optional: true},
+	}},
+	FilterSpec{Name: "expire", Args: []ArgSpec{
+		{Name: "timestamp", Type: ArgInt, Min: num(0)},
+	}},
+	FilterSpec{Name: "preview"},
+	FilterSpec{Name: "raw"},
+	// output filters
+	FilterSpec{Name: "format", Args: []ArgSpec{
+		{Name: "format", Type: ArgString, Keywords: []string{
+			"jpeg", "jpg", "png", "gif", "webp", "tiff", "avif", "heif", "jp2", "jxl", "bmp", "pdf", "svg", "magick",
+		}},
+	}},
+	FilterSpec{Name: "quality", Args: []ArgSpec{
+		{Name: "amount", Type: ArgInt, Min: num(0), Max: num(100)},
+	}},
+	FilterSpec{Name: "autojpg"},
+	FilterSpec{Name: "max_bytes", Args: []ArgSpec{
+		{Name: "amount", Type: ArgInt, Min: num(0)},
+	}},
+	FilterSpec{Name: "target_quality", Args: []ArgSpec{
+		{Name: "ssim", Type: ArgFloat, Min: num(0), Max: num(1)},
+	}},
+	FilterSpec{Name: "palette"},
+	FilterSpec{Name: "bitdepth", Args: []ArgSpec{
+		{Name: "bitdepth", Type: ArgInt, Min: num(1), Max: num(8)},
+	}},
+	FilterSpec{Name: "compression", Args: []ArgSpec{
+		{Name: "level", Type: ArgInt, Min: num(0), Max: num(9)},
+	}},
+	FilterSpec{Name: "lossless", Args: []ArgSpec{
+		{Name: "enabled", Type: ArgBool, Optional: true},
+	}},
+	FilterSpec{Name: "near_lossless", Args: []ArgSpec{
+		{Name: "enabled", Type: ArgBool, Optional: true},
+	}},
+	FilterSpec{Name: "effort", Args: []ArgSpec{
+		{Name: "level", Type: ArgInt, Min: num(0), Max: num(10)},
+	}},
+	FilterSpec{Name: "subsampling", Args: []ArgSpec{
+		{Name: "mode", Type: ArgString, Keywords: []string{"444", "420", "auto"}},
+	}},
+	FilterSpec{Name: "progressive", Args: []ArgSpec{
+		{Name: "enabled", Type: ArgBool, Optional: true},
+	}},
+	FilterSpec{Name: "trellis", Args: []ArgSpec{
+		{Name: "enabled", Type: ArgBool, Optional: true},
+	}},
+	FilterSpec{Name: "dither", Args: []ArgSpec{
+		{Name: "amount", Type: ArgFloat, Min: num(0), Max: num(1)},
+	}},
+	FilterSpec{Name: "strip_exif"},
+	FilterSpec{Name: "strip_icc"},
+	FilterSpec{Name: "strip_metadata"},
+	// loading filters
+	FilterSpec{Name: "max_frames", Args: []ArgSpec{
+		{Name: "n", Type: ArgInt, Min: num(1)},
+	}},
+	FilterSpec{Name: "page", Args: []ArgSpec{
+		{Name: "num", Type: ArgInt, Min: num(1)},
+	}},
+	FilterSpec{Name: "dpi", Args: []ArgSpec{
+		{Name: "num", Type: ArgInt, Min: num(1)},
+	}},
+	FilterSpec{Name: "orient", Args: []ArgSpec{
+		{Name: "angle", Type: ArgInt, Min: num(0), Max: num(360)},
+	}},
+	// resizing filters
+	FilterSpec{Name: "stretch"},
+	FilterSpec{Name: "upscale"},
+	FilterSpec{Name: "no_upscale"},
+	FilterSpec{Name: "resample", Args: []ArgSpec{
+		{Name: "kernel", Type: ArgString, Keywords: []string{
+			"nearest", "linear", "cubic", "mitchell", "lanczos2", "lanczos3",
+		}},
+	}},
+	FilterSpec{Name: "focal", Args: []ArgSpec{
+		{Name: "region", Type: ArgString},
+		{Name: "y", Type: ArgFloat, Optional: true, Min: num(0)},
+	}},
+	FilterSpec{Name: "redact", Args: []ArgSpec{
+		{Name: "region", Type: ArgRegion, Min: num(0)},
+		{Name: "mode", Type: ArgString, Optional: true},
+	}},
+	FilterSpec{Name: "pixelate", Args: []ArgSpec{
+		{Name: "size", Type: ArgInt, Min: num(2)},
+	}},
+	FilterSpec{Name: "proportion", Args: []ArgSpec{
+		{Name: "percentage", Type: ArgFloat, Min: num(0)},
+	}},
+	FilterSpec{Name: "trim", Args: []ArgSpec{
+		{Name: "tolerance", Type: ArgInt, Optional: true, Min: num(0)},
+		{Name: "position", Type: ArgString, Optional: true, Keywords: []string{TrimByTopLeft, TrimByBottomRight}},
+	}},
+	FilterSpec{Name: "fill", Args: []ArgSpec{
+		{Name: "color", Type: ArgColor},
+		{Name: "sigma", Type: ArgFloat, Optional: true, Min: num(0)},
+		{Name: "brightness", Type: ArgInt, Optional: true, Min: num(-100), Max: num(100)},
+	}},
+	FilterSpec{Name: "padding", Args: []ArgSpec{
+		{Name: "color", Type: ArgColor},
+		{Name: "left", Type: ArgInt, Min: num(0)},
+		{Name: "top", Type: ArgInt, Optional: true, Min: num(0)},
+		{Name: "right", Type: ArgInt, Optional: true, Min: num(0)},
+		{Name: "bottom", Type: ArgInt, Optional: true, Min: num(0)},
+	}},
+	FilterSpec{Name: "rotate", Args: []ArgSpec{
+		{Name: "angle", Type: ArgFloat, Min: num(-360), Max: num(360)},
+		{Name: "color", Type: ArgColor, Optional: true},
+	}},
+	FilterSpec{Name: "affine", Args: []ArgSpec{
+		{Name: "a", Type: ArgFloat},
+		{Name: "b", Type: ArgFloat},
+		{Name: "c", Type: ArgFloat},
+		{Name: "d", Type: ArgFloat},
+		{Name: "color", Type: ArgColor, Optional: true},
+	}},
+	FilterSpec{Name: "perspective", Args: []ArgSpec{
+		{Name: "x1", Type: ArgFloat},
+		{Name: "y1", Type: ArgFloat},
+		{Name: "x2", Type: ArgFloat},
+		{Name: "y2", Type: ArgFloat},
+		{Name: "x3", Type: ArgFloat},
+		{Name: "y3", Type: ArgFloat},
+		{Name: "x4", Type: ArgFloat},
+		{Name: "y4", Type: ArgFloat},
+		{Name: "color", Type: ArgColor, Optional: true},
+	}},
+	FilterSpec{Name: "set_frames", Args: []ArgSpec{
+		{Name: "n", Type: ArgInt, Min: num(1)},
+		{Name: "delay", Type: ArgInt, Optional: true, Min: num(0)},
+	}},
+	// compositing filters
+	FilterSpec{Name: "watermark", Args: []ArgSpec{
+		{Name: "image", Type: ArgString},
+		{Name: "x", Type: ArgPosition, Optional: true, Keywords: []string{HAlignLeft, HAlignRight, "center", "repeat"}},
+		{Name: "y", Type: ArgPosition, Optional: true, Keywords: []string{VAlignTop, VAlignBottom, "center", "repeat"}},
+		{Name: "alpha", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+		{Name: "w_ratio", Type: ArgInt, Optional: true, Min: num(0), Keywords: []string{"none"}},
+		{Name: "h_ratio", Type: ArgInt, Optional: true, Min: num(0), Keywords: []string{"none"}},
+	}},
+	FilterSpec{Name: "layer", Args: []ArgSpec{
+		{Name: "image", Type: ArgString},
+		{Name: "x", Type: ArgPosition, Optional: true, Keywords: []string{HAlignLeft, HAlignRight, "center", "repeat"}},
+		{Name: "y", Type: ArgPosition, Optional: true, Keywords: []string{VAlignTop, VAlignBottom, "center", "repeat"}},
+		{Name: "alpha", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+		{Name: "blend", Type: ArgString, Optional: true, Keywords: []string{
+			"over", "add", "saturate", "multiply", "screen", "overlay", "darken", "lighten",
+			"color_dodge", "colour_dodge", "color_burn", "colour_burn", "hard_light", "soft_light",
+			"difference", "exclusion",
+		}},
+		{Name: "width", Type: ArgPosition, Optional: true, Keywords: []string{"none"}},
+		{Name: "height", Type: ArgPosition, Optional: true, Keywords: []string{"none"}},
+		{Name: "angle", Type: ArgFloat, Optional: true, Min: num(-360), Max: num(360)},
+	}},
+	FilterSpec{Name: "label", Args: []ArgSpec{
+		{Name: "text", Type: ArgString},
+		{Name: "x", Type: ArgPosition, Optional: true, Keywords: []string{HAlignLeft, HAlignRight, "center"}},
+		{Name: "y", Type: ArgPosition, Optional: true, Keywords: []string{VAlignTop, VAlignBottom, "center"}},
+		{Name: "size", Type: ArgInt, Optional: true, Min: num(0)},
+		{Name: "color", Type: ArgColor, Optional: true},
+		{Name: "alpha", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+		{Name: "font", Type: ArgString, Optional: true},
+	}},
+	FilterSpec{Name: "text", Args: []ArgSpec{
+		{Name: "text", Type: ArgString},
+		{Name: "x", Type: ArgPosition, Optional: true, Keywords: []string{HAlignLeft, HAlignRight, "center"}},
+		{Name: "y", Type: ArgPosition, Optional: true, Keywords: []string{VAlignTop, VAlignBottom, "center"}},
+		{Name: "size", Type: ArgInt, Optional: true, Min: num(0)},
+		{Name: "color", Type: ArgColor, Optional: true},
+		{Name: "alpha", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+		{Name: "font", Type: ArgString, Optional: true},
+		{Name: "options", Type: ArgString, Variadic: true},
+	}},
+	FilterSpec{Name: "border", Args: []ArgSpec{
+		{Name: "width", Type: ArgInt, Min: num(0)},
+		{Name: "color", Type: ArgColor, Optional: true},
+	}},
+	FilterSpec{Name: "shadow", Args: []ArgSpec{
+		{Name: "x", Type: ArgInt},
+		{Name: "y", Type: ArgInt},
+		{Name: "sigma", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+		{Name: "color", Type: ArgColor, Optional: true},
+		{Name: "opacity", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+	}},
+	FilterSpec{Name: "vignette", Args: []ArgSpec{
+		{Name: "strength", Type: ArgFloat, Min: num(0), Max: num(100)},
+	}},
+	FilterSpec{Name: "mask", Args: []ArgSpec{
+		{Name: "image", Type: ArgString},
+		{Name: "invert", Type: ArgString, Optional: true, Keywords: []string{"invert", "true", "false", "1", "0"}},
+	}},
+	FilterSpec{Name: "round_corner", Args: []ArgSpec{
+		{Name: "rx", Type: ArgInt, Min: num(0)},
+		{Name: "ry", Type: ArgInt, Optional: true, Min: num(0)},
+		{Name: "color", Type: ArgColor, Optional: true},
+	}},
+	FilterSpec{Name: "background_color", Args: []ArgSpec{
+		{Name: "color", Type: ArgColor},
+	}},
+	// color filters
+	FilterSpec{Name: "grayscale"},
+	FilterSpec{Name: "brightness", Args: []ArgSpec{
+		{Name: "amount", Type: ArgFloat, Min: num(-100), Max: num(100)},
+	}},
+	FilterSpec{Name: "contrast", Args: []ArgSpec{
+		{Name: "amount", Type: ArgFloat, Min: num(-100), Max: num(100)},
+	}},
+	FilterSpec{Name: "hue", Args: []ArgSpec{
+		{Name: "angle", Type: ArgFloat},
+	}},
+	FilterSpec{Name: "saturation", Args: []ArgSpec{
+		{Name: "amount", Type: ArgFloat, Min: num(-100), Max: num(100)},
+	}},
+	FilterSpec{Name: "modulate", Args: []ArgSpec{
+		{Name: "brightness", Type: ArgFloat},
+		{Name: "saturation", Type: ArgFloat},
+		{Name: "hue", Type: ArgFloat},
+	}},
+	FilterSpec{Name: "gamma", Args: []ArgSpec{
+		{Name: "gamma", Type: ArgFloat, Min: num(0.01), Max: num(100)},
+	}},
+	FilterSpec{Name: "levels", Args: []ArgSpec{
+		{Name: "black", Type: ArgFloat, Min: num(0), Max: num(255)},
+		{Name: "white", Type: ArgFloat, Min: num(0), Max: num(255)},
+		{Name: "gamma", Type: ArgFloat, Optional: true, Min: num(0.01), Max: num(100)},
+	}},
+	FilterSpec{Name: "curves", Args: []ArgSpec{
+		{Name: "points", Type: ArgString, Variadic: true},
+	}},
+	FilterSpec{Name: "normalize", Args: []ArgSpec{
+		{Name: "clip", Type: ArgFloat, Optional: true, Min: num(0), Max: num(49)},
+	}},
+	FilterSpec{Name: "equalize"},
+	FilterSpec{Name: "sepia", Args: []ArgSpec{
+		{Name: "amount", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+	}},
+	FilterSpec{Name: "duotone", Args: []ArgSpec{
+		{Name: "shadow", Type: ArgColor},
+		{Name: "highlight", Type: ArgColor},
+	}},
+	FilterSpec{Name: "colorize", Args: []ArgSpec{
+		{Name: "color", Type: ArgColor},
+		{Name: "amount", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+	}},
+	FilterSpec{Name: "gradient", Args: []ArgSpec{
+		{Name: "angle", Type: ArgFloat, Min: num(-360), Max: num(360)},
+		{Name: "color1", Type: ArgColor},
+		{Name: "color2", Type: ArgColor},
+		{Name: "opacity", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+	}},
+	FilterSpec{Name: "transparent", Args: []ArgSpec{
+		{Name: "color", Type: ArgColor},
+		{Name: "tolerance", Type: ArgFloat, Min: num(0), Max: num(100)},
+		{Name: "feather", Type: ArgFloat, Optional: true, Min: num(0), Max: num(100)},
+		{Name: "mode", Type: ArgString, Optional: true, Keywords: []string{"all", "flood"}},
+	}},
+	FilterSpec{Name: "rgb", Args: []ArgSpec{
+		{Name: "r", Type: ArgFloat, Min: num(-100), Max: num(100)},
+		{Name: "g", Type: ArgFloat, Min: num(-100), Max: num(100)},
+		{Name: "b", Type: ArgFloat, Min: num(-100), Max: num(100)},
+	}},
+	FilterSpec{Name: "convolution", Args: []ArgSpec{
+		{Name: "matrix", Type: ArgMatrix, Max: num(MaxConvolutionSize * MaxConvolutionSize)},
+		{Name: "columns", Type: ArgInt, Min: num(1), Max: num(MaxConvolutionSize)},
+		{Name: "normalize", Type: ArgBool, Optional: true},
+	}},
+	FilterSpec{Name: "blur", Args: []ArgSpec{
+		{Name: "radius", Type: ArgFloat, Min: num(0)},
+		{Name: "sigma", Type: ArgFloat, Optional: true, Min: num(0)},
+	}},
+	FilterSpec{Name: "sharpen", Args: []ArgSpec{
+		{Name: "amount", Type: ArgFloat, Min: num(0)},
+		{Name: "radius", Type: ArgFloat, Optional: true, Min: num(0)},
+		{Name: "luminance_only", Type: ArgBool, Optional: true},
+	}},
+)
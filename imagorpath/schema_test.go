@@ -0,0 +1,190 @@
+package imagorpath
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		result TypedFilter
+		err    string
+	}{
+		{
+			name:   "no args",
+			filter: Filter{Name: "grayscale"},
+			result: TypedFilter{Filter: Filter{Name: "grayscale"}},
+		},
+		{
+			name:   "int normalized",
+			filter: Filter{Name: "quality", Args: " 080"},
+			result: TypedFilter{Filter: Filter{Name: "quality", Args: "80"}, TypedArgs: []interface{}{80}},
+		},
+		{
+			name:   "float normalized",
+			filter: Filter{Name: "blur", Args: "5.0,2.50"},
+			result: TypedFilter{Filter: Filter{Name: "blur", Args: "5,2.5"}, TypedArgs: []interface{}{5.0, 2.5}},
+		},
+		{
+			name:   "color",
+			filter: Filter{Name: "fill", Args: "FFCC00"},
+			result: TypedFilter{Filter: Filter{Name: "fill", Args: "ffcc00"}, TypedArgs: []interface{}{"ffcc00"}},
+		},
+		{
+			name:   "fill blur",
+			filter: Filter{Name: "fill", Args: "blur,20.0,-30"},
+			result: TypedFilter{Filter: Filter{Name: "fill", Args: "blur,20,-30"}, TypedArgs: []interface{}{"blur", 20.0, -30}},
+		},
+		{
+			name:   "layer",
+			filter: Filter{Name: "layer", Args: "logo.png,10p,center,0,multiply,30p,none,-15"},
+			result: TypedFilter{Filter: Filter{Name: "layer", Args: "logo.png,10p,center,0,multiply,30p,none,-15"}, TypedArgs: []interface{}{"logo.png", "10p", "center", 0.0, "multiply", "30p", "none", -15.0}},
+		},
+		{
+			name:   "optional args",
+			filter: Filter{Name: "round_corner", Args: "10,,red"},
+			result: TypedFilter{Filter: Filter{Name: "round_corner", Args: "10,,red"}, TypedArgs: []interface{}{10, nil, "red"}},
+		},
+		{
+			name:   "invalid keyword",
+			filter: Filter{Name: "watermark", Args: "foo.png,repeat,-10,50,20p,none"},
+			err:    "invalid filter watermark: argument w_ratio expects an integer or one of none",
+		},
+		{
+			name:   "watermark",
+			filter: Filter{Name: "watermark", Args: "foo.png,20p,-0.50,50,20,none"},
+			result: TypedFilter{
+				Filter:    Filter{Name: "watermark", Args: "foo.png,20p,-0.5,50,20,none"},
+				TypedArgs: []interface{}{"foo.png", "20p", -0.5, 50.0, 20, "none"},
+			},
+		},
+		{
+			name:   "keyword string",
+			filter: Filter{Name: "format", Args: "bmpp"},
+			err:    "invalid filter format: argument format expects one of jpeg, jpg, png, gif, webp, tiff, avif, heif, jp2, jxl, bmp, pdf, svg, magick",
+		},
+		{
+			name:   "unknown filter",
+			filter: Filter{Name: "blurr", Args: "5"},
+			err:    "invalid filter blurr: unknown filter",
+		},
+		{
+			name:   "not a number",
+			filter: Filter{Name: "brightness", Args: "abc"},
+			err:    "invalid filter brightness: argument amount expects a number",
+		},
+		{
+			name:   "out of range",
+			filter: Filter{Name: "quality", Args: "101"},
+			err:    "invalid filter quality: argument amount must be at most 100",
+		},
+		{
+			name:   "below range",
+			filter: Filter{Name: "page", Args: "0"},
+			err:    "invalid filter page: argument num must be at least 1",
+		},
+		{
+			name:   "required",
+			filter: Filter{Name: "rgb", Args: "1,2"},
+			err:    "invalid filter rgb: argument b is required",
+		},
+		{
+			name:   "too many",
+			filter: Filter{Name: "grayscale", Args: "1"},
+			err:    "invalid filter grayscale: expects at most 0 arguments",
+		},
+		{
+			name:   "redact region",
+			filter: Filter{Name: "redact", Args: "10x20:110.0x80,blur"},
+			result: TypedFilter{Filter: Filter{Name: "redact", Args: "10x20:110x80,blur"}, TypedArgs: []interface{}{"10x20:110x80", "blur"}},
+		},
+		{
+			name:   "redact coordinates",
+			filter: Filter{Name: "redact", Args: "0.1,0.2,0.50,0.6"},
+			result: TypedFilter{Filter: Filter{Name: "redact", Args: "0.1,0.2,0.5,0.6"}, TypedArgs: []interface{}{0.1, 0.2, 0.5, 0.6}},
+		},
+		{
+			name:   "redact coordinates mode",
+			filter: Filter{Name: "redact", Args: "10,20,110,80,ff0000"},
+			result: TypedFilter{Filter: Filter{Name: "redact", Args: "10,20,110,80,ff0000"}, TypedArgs: []interface{}{10.0, 20.0, 110.0, 80.0, "ff0000"}},
+		},
+		{
+			name:   "redact invalid region",
+			filter: Filter{Name: "redact", Args: "10x20:110,blur"},
+			err:    "invalid filter redact: argument region expects AxB:CxD",
+		},
+		{
+			name:   "redact missing coordinates",
+			filter: Filter{Name: "redact", Args: "10,20,110"},
+			err:    "invalid filter redact: argument region expects AxB:CxD or left,top,right,bottom",
+		},
+		{
+			name:   "redact too many",
+			filter: Filter{Name: "redact", Args: "10x20:110x80,blur,1"},
+			err:    "invalid filter redact: expects at most 2 arguments",
+		},
+		{
+			name:   "convolution matrix",
+			filter: Filter{Name: "convolution", Args: "1; 2.0;1,3,true"},
+			result: TypedFilter{Filter: Filter{Name: "convolution", Args: "1;2;1,3,true"}, TypedArgs: []interface{}{[]float64{1, 2, 1}, 3, true}},
+		},
+		{
+			name:   "convolution invalid matrix",
+			filter: Filter{Name: "convolution", Args: "1;a;1,3"},
+			err:    "invalid filter convolution: argument matrix expects numbers separated by ;",
+		},
+		{
+			name:   "convolution matrix too large",
+			filter: Filter{Name: "convolution", Args: strings.Repeat("1;", 225) + "1,15"},
+			err:    "invalid filter convolution: argument matrix must have at most 225 items",
+		},
+		{
+			name:   "convolution columns too large",
+			filter: Filter{Name: "convolution", Args: "1;1,16"},
+			err:    "invalid filter convolution: argument columns must be at most 15",
+		},
+		{
+			name:   "invalid color",
+			filter: Filter{Name: "fill", Args: "#fff"},
+			err:    "invalid filter fill: argument color expects a color name or hexadecimal rgb expression",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := DefaultFilterSchema.Validate(test.filter)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.result, res)
+		})
+	}
+}
+
+func TestFilterSchemaWith(t *testing.T) {
+	schema := DefaultFilterSchema.With(FilterSpec{
+		Name: "custom", Args: []ArgSpec{{Name: "args", Type: ArgInt, Variadic: true}},
+	})
+	_, err := DefaultFilterSchema.Validate(Filter{Name: "custom"})
+	assert.ErrorContains(t, err, "unknown filter")
+
+	filters, err := schema.ValidateFilters(Filters{
+		{Name: "custom", Args: "1,02,3"},
+		{Name: "quality", Args: "070"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Filters{
+		{Name: "custom", Args: "1,2,3"},
+		{Name: "quality", Args: "70"},
+	}, filters)
+
+	_, err = schema.ValidateFilters(Filters{{Name: "custom", Args: "1,a"}})
+	assert.EqualError(t, err, "invalid filter custom: argument args expects an integer")
+}
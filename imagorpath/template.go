@@ -0,0 +1,241 @@
+package imagorpath
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// TemplateHasher StorageHasher and ResultStorageHasher by path template with {variable} placeholders,
+// e.g. {image_dir}/{image_name}/{width}x{height}/{digest:16}.{ext}
+//
+// Image variables:
+//
+//	{image} image path
+//	{image_dir} image directory
+//	{image_name} image file name without extension
+//	{image_ext} image file extension without dot
+//	{image_digest} or {image_digest:n} SHA1 hex digest of image path, truncated to n characters
+//
+// Result variables, for result storage only:
+//
+//	{digest} or {digest:n} SHA1 hex digest of endpoint path, truncated to n characters
+//	{width} {height} output dimensions
+//	{ext} selected output format, or the image extension if not specified
+type TemplateHasher struct {
+	template string
+	segments []templateSegment
+}
+
+type templateSegment struct {
+	text     string
+	variable string
+	size     int
+}
+
+var imageTemplateVariables = map[string]bool{
+	"image": true, "image_dir": true, "image_name": true, "image_ext": true, "image_digest": true,
+}
+
+var resultTemplateVariables = map[string]bool{
+	"digest": true, "width": true, "height": true, "ext": true,
+}
+
+// minTemplateDigestSize min digest size identifying the image in storage path template
+const minTemplateDigestSize = 16
+
+// NewTemplateStorageHasher create StorageHasher from path template with image variables.
+// Template must contain {image} or {image_digest} of at least 16 characters,
+// so that different images do not collide
+func NewTemplateStorageHasher(template string) (*TemplateHasher, error) {
+	h, err := parseTemplate(template, false)
+	if err != nil {
+		return nil, err
+	}
+	if !h.identifiesImage() {
+		return nil, fmt.Errorf("invalid template %s: missing {image} or {image_digest} variable", template)
+	}
+	return h, nil
+}
+
+// NewTemplateResultStorageHasher create ResultStorageHasher from path template with image and result variables.
+// Template must contain {digest} of at least 16 characters so that different params do not collide
+func NewTemplateResultStorageHasher(template string) (*TemplateHasher, error) {
+	h, err := parseTemplate(template, true)
+	if err != nil {
+		return nil, err
+	}
+	if !h.hasDigest("digest") {
+		return nil, fmt.Errorf("invalid template %s: missing {digest} variable of at least %d characters",
+			template, minTemplateDigestSize)
+	}
+	return h, nil
+}
+
+// Hash implements StorageHasher interface
+func (h *TemplateHasher) Hash(image string) string {
+	return h.execute(Params{Image: image})
+}
+
+// HashResult implements ResultStorageHasher interface
+func (h *TemplateHasher) HashResult(p Params) string {
+	if p.Path == "" {
+		p.Path = GeneratePath(p)
+	}
+	return h.execute(p)
+}
+
+// String returns the path template
+func (h *TemplateHasher) String() string {
+	return h.template
+}
+
+// hasDigest reports whether template contains the digest variable of at least minTemplateDigestSize
+func (h *TemplateHasher) hasDigest(digest string) bool {
+	for _, seg := range h.segments {
+		if seg.variable == digest && (seg.size == 0 || seg.size >= minTemplateDigestSize) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *TemplateHasher) identifiesImage() bool {
+	for _, seg := range h.segments {
+		if seg.variable == "image" {
+			return true
+		}
+	}
+	return h.hasDigest("image_digest")
+}
+
+func (h *TemplateHasher) execute(p Params) string {
+	var dir, name, ext string
+	var base = path.Base(p.Image)
+	if idx := strings.LastIndex(p.Image, "/"); idx > -1 {
+		dir = p.Image[:idx]
+	}
+	if idx := strings.LastIndex(base, "."); idx > 0 {
+		name, ext = base[:idx], base[idx+1:]
+	} else {
+		name = base
+	}
+	// separators of the template are dropped next to empty variables,
+	// e.g. image without directory or extension, keeping those within variable values
+	var sb strings.Builder
+	var empty bool
+	var textEnd = -1
+	for _, seg := range h.segments {
+		if seg.variable == "" {
+			text := seg.text
+			if empty && strings.HasPrefix(text, "/") &&
+				(sb.Len() == 0 || strings.HasSuffix(sb.String(), "/")) {
+				text = text[1:]
+			}
+			sb.WriteString(text)
+			empty = false
+			textEnd = sb.Len()
+			continue
+		}
+		var value string
+		switch seg.variable {
+		case "image":
+			value = p.Image
+		case "image_dir":
+			value = dir
+		case "image_name":
+			value = name
+		case "image_ext":
+			value = ext
+		case "image_digest":
+			value = hexDigest(p.Image, seg.size)
+		case "digest":
+			value = hexDigest(p.Path, seg.size)
+		case "width":
+			value = strconv.Itoa(p.Width)
+		case "height":
+			value = strconv.Itoa(p.Height)
+		case "ext":
+			value = resultExt(p, ext)
+		}
+		if value == "" && textEnd == sb.Len() && strings.HasSuffix(sb.String(), ".") {
+			// extension separator of empty variable
+			res := strings.TrimSuffix(sb.String(), ".")
+			sb.Reset()
+			sb.WriteString(res)
+			textEnd = sb.Len()
+		}
+		sb.WriteString(value)
+		empty = value == ""
+	}
+	var res = sb.String()
+	if empty && textEnd == len(res) {
+		res = strings.TrimSuffix(res, "/")
+	}
+	return res
+}
+
+func resultExt(p Params, ext string) string {
+	if p.Meta {
+		return "json"
+	}
+	for _, filter := range p.Filters {
+		if filter.Name == "format" {
+			ext = filter.Args
+		}
+	}
+	return ext
+}
+
+func hexDigest(s string, size int) string {
+	var digest = sha1.Sum([]byte(s))
+	var hash = hex.EncodeToString(digest[:])
+	if size > 0 && size < len(hash) {
+		return hash[:size]
+	}
+	return hash
+}
+
+func parseTemplate(template string, isResult bool) (*TemplateHasher, error) {
+	h := &TemplateHasher{template: template}
+	var rest = template
+	for rest != "" {
+		start := strings.IndexAny(rest, "{}")
+		if start == -1 {
+			h.segments = append(h.segments, templateSegment{text: rest})
+			break
+		}
+		if rest[start] == '}' {
+			return nil, fmt.Errorf("invalid template %s: unexpected }", template)
+		}
+		if start > 0 {
+			h.segments = append(h.segments, templateSegment{text: rest[:start]})
+		}
+		end := strings.IndexAny(rest[start+1:], "{}")
+		if end == -1 || rest[start+1+end] != '}' {
+			return nil, fmt.Errorf("invalid template %s: unclosed {", template)
+		}
+		expr := rest[start+1 : start+1+end]
+		rest = rest[start+1+end+1:]
+		name, arg, hasArg := strings.Cut(expr, ":")
+		if !imageTemplateVariables[name] && !(isResult && resultTemplateVariables[name]) {
+			return nil, fmt.Errorf("invalid template %s: unknown variable {%s}", template, expr)
+		}
+		seg := templateSegment{variable: name}
+		if hasArg {
+			if name != "digest" && name != "image_digest" {
+				return nil, fmt.Errorf("invalid template %s: unexpected argument {%s}", template, expr)
+			}
+			size, err := strconv.Atoi(arg)
+			if err != nil || size < 1 || size > sha1.Size*2 {
+				return nil, fmt.Errorf("invalid template %s: invalid digest size {%s}", template, expr)
+			}
+			seg.size = size
+		}
+		h.segments = append(h.segments, seg)
+	}
+	return h, nil
+}
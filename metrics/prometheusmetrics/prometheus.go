@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/kumparan/imagor/seekstream"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
@@ -23,8 +24,9 @@ var (
 type PrometheusMetrics struct {
 	http.Server
 
-	Path   string
-	Logger *zap.Logger
+	Path         string
+	Logger       *zap.Logger
+	MemoryBudget *seekstream.MemoryBudget
 }
 
 // New create new metrics PrometheusMetrics
@@ -51,8 +53,42 @@ func New(options ...Option) *PrometheusMetrics {
 
 // Startup prometheus metrics server
 func (s *PrometheusMetrics) Startup(_ context.Context) error {
-	if err := prometheus.Register(httpRequestDuration); err != nil {
-		return err
+	collectors := []prometheus.Collector{httpRequestDuration}
+	if budget := s.MemoryBudget; budget != nil {
+		collectors = append(collectors,
+			prometheus.NewGaugeFunc(
+				prometheus.GaugeOpts{
+					Name: "imagor_buffer_memory_bytes",
+					Help: "Bytes of in-memory image buffers within the memory budget",
+				},
+				func() float64 {
+					return float64(budget.Used())
+				},
+			),
+			prometheus.NewCounterFunc(
+				prometheus.CounterOpts{
+					Name: "imagor_buffer_spills_total",
+					Help: "Number of image buffers spilled from memory to temp file",
+				},
+				func() float64 {
+					return float64(budget.Spills())
+				},
+			),
+			prometheus.NewCounterFunc(
+				prometheus.CounterOpts{
+					Name: "imagor_buffer_spilled_bytes_total",
+					Help: "Bytes written to temp file by spilled image buffers",
+				},
+				func() float64 {
+					return float64(budget.SpilledBytes())
+				},
+			),
+		)
+	}
+	for _, collector := range collectors {
+		if err := prometheus.Register(collector); err != nil {
+			return err
+		}
 	}
 
 	go func() {
@@ -94,3 +130,10 @@ func WithLogger(logger *zap.Logger) Option {
 		}
 	}
 }
+
+// WithMemoryBudget with imagor memory budget option, exposing buffer metrics
+func WithMemoryBudget(budget *seekstream.MemoryBudget) Option {
+	return func(s *PrometheusMetrics) {
+		s.MemoryBudget = budget
+	}
+}
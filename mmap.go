@@ -0,0 +1,79 @@
+package imagor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+)
+
+var errMmapUnsupported = errors.New("mmap unsupported")
+
+// mmapFile reference counted memory-mapped file.
+// The file is mapped on first acquire and unmapped when all references are released
+type mmapFile struct {
+	filepath string
+	mu       sync.Mutex
+	data     []byte
+	refs     int
+}
+
+func newMmapFile(filepath string) *mmapFile {
+	m := &mmapFile{filepath: filepath}
+	// safety net for leaked references, data is only reachable through m
+	runtime.SetFinalizer(m, func(m *mmapFile) {
+		if m.data != nil {
+			_ = munmap(m.data)
+			m.data = nil
+		}
+	})
+	return m
+}
+
+// acquire maps the file if not yet mapped and returns data with release func
+func (m *mmapFile) acquire() ([]byte, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.refs == 0 {
+		data, err := mmap(m.filepath)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.data = data
+	}
+	m.refs++
+	var once sync.Once
+	return m.data, func() {
+		once.Do(m.release)
+	}, nil
+}
+
+func (m *mmapFile) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refs--
+	if m.refs == 0 && m.data != nil {
+		_ = munmap(m.data)
+		m.data = nil
+	}
+}
+
+// mmapReader io.ReadSeekCloser of mapped data, releasing the mapping reference on close
+type mmapReader struct {
+	*bytes.Reader
+	release func()
+}
+
+func (r *mmapReader) Close() error {
+	r.release()
+	return nil
+}
+
+func newMmapReader(m *mmapFile) (io.ReadSeekCloser, int64, error) {
+	data, release, err := m.acquire()
+	if err != nil {
+		return nil, 0, err
+	}
+	return &mmapReader{Reader: bytes.NewReader(data), release: release}, int64(len(data)), nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package imagor
+
+func mmap(_ string) ([]byte, error) {
+	return nil, errMmapUnsupported
+}
+
+func munmap(_ []byte) error {
+	return errMmapUnsupported
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package imagor
+
+import (
+	"os"
+	"syscall"
+)
+
+func mmap(filepath string) ([]byte, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.Size() <= 0 || int64(int(stat.Size())) != stat.Size() {
+		return nil, errMmapUnsupported
+	}
+	return syscall.Mmap(int(file.Fd()), 0, int(stat.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func munmap(data []byte) error {
+	return syscall.Munmap(data)
+}
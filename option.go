@@ -2,7 +2,9 @@ package imagor
 
 import (
 	"github.com/kumparan/imagor/imagorpath"
+	"github.com/kumparan/imagor/seekstream"
 	"go.uber.org/zap"
+	"strings"
 	"time"
 )
 
@@ -152,6 +154,13 @@ func WithAutoWebP(enable bool) Option {
 	}
 }
 
+// WithAutoJXL with auto JPEG XL option based on browser Accept header
+func WithAutoJXL(enable bool) Option {
+	return func(app *Imagor) {
+		app.AutoJXL = enable
+	}
+}
+
 // WithAutoAVIF experimental with auto AVIF option based on browser Accept header
 func WithAutoAVIF(enable bool) Option {
 	return func(app *Imagor) {
@@ -194,6 +203,73 @@ func WithDisableParamsEndpoint(disabled bool) Option {
 	}
 }
 
+// WithDisableJSONParams with disable JSON params POST request body
+func WithDisableJSONParams(disabled bool) Option {
+	return func(app *Imagor) {
+		app.DisableJSONParams = disabled
+	}
+}
+
+// WithJSONParamsMaxBytes with maximum JSON params request body size in bytes
+func WithJSONParamsMaxBytes(maxBytes int64) Option {
+	return func(app *Imagor) {
+		if maxBytes > 0 {
+			app.JSONParamsMaxBytes = maxBytes
+		}
+	}
+}
+
+// WithMemoryBudget with memory budget in bytes for in-memory blob buffers,
+// shared across all in-flight blobs of the instance. Buffers spill to temp file beyond the budget. Unlimited if 0
+func WithMemoryBudget(budget int64) Option {
+	return func(app *Imagor) {
+		if budget >= 0 {
+			app.MemoryBudget = seekstream.NewMemoryBudget(budget)
+		}
+	}
+}
+
+// WithMemoryThreshold with in-memory buffer threshold in bytes of blob streams with unknown size,
+// spilling to temp file beyond the threshold
+func WithMemoryThreshold(threshold int64) Option {
+	return func(app *Imagor) {
+		if threshold > 0 {
+			app.MemoryThreshold = threshold
+		}
+	}
+}
+
+// WithCardTemplates with social card templates by name option, enabling the card endpoint
+func WithCardTemplates(templates map[string]imagorpath.CardTemplate) Option {
+	return func(app *Imagor) {
+		if len(templates) > 0 {
+			if app.CardTemplates == nil {
+				app.CardTemplates = map[string]imagorpath.CardTemplate{}
+			}
+			for name, t := range templates {
+				app.CardTemplates[name] = t
+			}
+		}
+	}
+}
+
+// WithCardTemplateKeyPrefix with key prefix option of card templates loaded from storages and loaders
+// as {prefix}{name}.json, enabling the card endpoint
+func WithCardTemplateKeyPrefix(prefix string) Option {
+	return func(app *Imagor) {
+		app.CardTemplateKeyPrefix = prefix
+	}
+}
+
+// WithCardPathPrefix with card endpoint path prefix option, /card by default
+func WithCardPathPrefix(pathPrefix string) Option {
+	return func(app *Imagor) {
+		if pathPrefix = strings.Trim(pathPrefix, "/"); pathPrefix != "" {
+			app.CardPathPrefix = "/" + pathPrefix + "/"
+		}
+	}
+}
+
 // WithDebug with debug option
 func WithDebug(debug bool) Option {
 	return func(app *Imagor) {
@@ -234,3 +310,35 @@ func WithImageErrorFallback(base64Image string) Option {
 		app.ImageErrorFallback = base64Image
 	}
 }
+
+// WithImgproxy with imgproxy compatible URL parser mounted under path prefix option
+func WithImgproxy(pathPrefix string, parser *imagorpath.ImgproxyParser) Option {
+	return func(app *Imagor) {
+		if pathPrefix != "" && parser != nil {
+			app.ImgproxyPathPrefix = "/" + strings.Trim(pathPrefix, "/") + "/"
+			app.ImgproxyParser = parser
+		}
+	}
+}
+
+// WithStrictFilters with strict filters option, rejecting unknown filters and invalid arguments
+func WithStrictFilters(enabled bool) Option {
+	return func(app *Imagor) {
+		app.StrictFilters = enabled
+	}
+}
+
+// WithFilterSpecs with additional filter specs for the filter schema option
+func WithFilterSpecs(specs ...imagorpath.FilterSpec) Option {
+	return func(app *Imagor) {
+		app.FilterSchema = app.FilterSchema.With(specs...)
+	}
+}
+
+// WithCanonicalResultKey with canonical result key option,
+// deduplicating result storage and request suppression of equivalent params
+func WithCanonicalResultKey(enabled bool) Option {
+	return func(app *Imagor) {
+		app.CanonicalResultKey = enabled
+	}
+}
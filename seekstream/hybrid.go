@@ -0,0 +1,229 @@
+package seekstream
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// MemoryBudget memory budget shared across HybridBuffers,
+// limiting the total in-memory buffer size of all in-flight streams
+type MemoryBudget struct {
+	limit        atomic.Int64
+	used         atomic.Int64
+	spills       atomic.Int64
+	spilledBytes atomic.Int64
+}
+
+// NewMemoryBudget new memory budget providing limit in bytes. Unlimited if 0
+func NewMemoryBudget(limit int64) *MemoryBudget {
+	m := &MemoryBudget{}
+	m.SetLimit(limit)
+	return m
+}
+
+// SetLimit sets budget limit in bytes. Unlimited if 0
+func (m *MemoryBudget) SetLimit(limit int64) {
+	m.limit.Store(limit)
+}
+
+// Limit returns budget limit in bytes
+func (m *MemoryBudget) Limit() int64 {
+	return m.limit.Load()
+}
+
+// Used returns bytes currently reserved by in-memory buffers
+func (m *MemoryBudget) Used() int64 {
+	return m.used.Load()
+}
+
+// Spills returns the number of buffers spilled to temp file
+func (m *MemoryBudget) Spills() int64 {
+	return m.spills.Load()
+}
+
+// SpilledBytes returns the total bytes written to temp file by spilled buffers
+func (m *MemoryBudget) SpilledBytes() int64 {
+	return m.spilledBytes.Load()
+}
+
+func (m *MemoryBudget) reserve(n int64) bool {
+	for {
+		used := m.used.Load()
+		if limit := m.limit.Load(); limit > 0 && used+n > limit {
+			return false
+		}
+		if m.used.CompareAndSwap(used, used+n) {
+			return true
+		}
+	}
+}
+
+func (m *MemoryBudget) release(n int64) {
+	m.used.Add(-n)
+}
+
+// HybridBuffer Buffer implementation that keeps data in memory up to threshold,
+// and transparently spills to temp file beyond the threshold or memory budget
+type HybridBuffer struct {
+	threshold int64
+	budget    *MemoryBudget
+	dir       string
+	pattern   string
+
+	buf      []byte
+	reserved int64
+	file     *os.File
+	i        int64 // current reading index
+	s        int64 // size
+}
+
+// NewHybridBuffer new hybrid buffer providing expected size if known,
+// memory threshold in bytes, memory budget and temp file dir and pattern.
+// Memory budget is not constrained if nil
+func NewHybridBuffer(size, threshold int64, budget *MemoryBudget, dir, pattern string) *HybridBuffer {
+	b := &HybridBuffer{
+		threshold: threshold,
+		budget:    budget,
+		dir:       dir,
+		pattern:   pattern,
+	}
+	if size > 0 && size <= threshold {
+		// preallocate known size if within budget, otherwise grow on write
+		if b.reserve(size) {
+			b.buf = make([]byte, 0, size)
+		}
+	}
+	return b
+}
+
+// Spilled returns true if buffer spilled to temp file
+func (b *HybridBuffer) Spilled() bool {
+	return b.file != nil
+}
+
+// Read implements the io.Reader interface.
+func (b *HybridBuffer) Read(p []byte) (n int, err error) {
+	if b.i >= b.s {
+		return 0, io.EOF
+	}
+	if b.file != nil {
+		n, err = b.file.ReadAt(p[:min(int64(len(p)), b.s-b.i)], b.i)
+	} else {
+		n = copy(p, b.buf[b.i:b.s])
+	}
+	b.i += int64(n)
+	return
+}
+
+// Write implements the io.Writer interface.
+func (b *HybridBuffer) Write(p []byte) (n int, err error) {
+	end := b.i + int64(len(p))
+	if b.file == nil && !b.grow(end) {
+		if err = b.spill(); err != nil {
+			return
+		}
+	}
+	if b.file != nil {
+		n, err = b.file.WriteAt(p, b.i)
+		if b.budget != nil {
+			b.budget.spilledBytes.Add(int64(n))
+		}
+	} else {
+		if int64(len(b.buf)) < end {
+			b.buf = b.buf[:end]
+		}
+		n = copy(b.buf[b.i:], p)
+	}
+	b.i += int64(n)
+	if b.i > b.s {
+		b.s = b.i
+	}
+	return
+}
+
+// Seek implements the io.Seeker interface
+func (b *HybridBuffer) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = b.i + offset
+	case io.SeekEnd:
+		abs = b.s + offset
+	}
+	if abs < 0 {
+		return 0, errors.New("invalid argument")
+	}
+	b.i = abs
+	return abs, nil
+}
+
+// Clear performs cleanup on stream close
+func (b *HybridBuffer) Clear() {
+	b.buf = nil
+	b.release()
+	if b.file != nil {
+		filename := b.file.Name()
+		_ = b.file.Close()
+		_ = os.Remove(filename)
+		b.file = nil
+	}
+}
+
+// grow ensures memory buffer capacity of size within threshold and budget
+func (b *HybridBuffer) grow(size int64) bool {
+	if size <= int64(cap(b.buf)) {
+		return true
+	}
+	if size > b.threshold {
+		return false
+	}
+	newCap := max(size, int64(cap(b.buf))*2)
+	newCap = min(newCap, b.threshold)
+	if !b.reserve(newCap - b.reserved) {
+		return false
+	}
+	buf := make([]byte, len(b.buf), newCap)
+	copy(buf, b.buf)
+	b.buf = buf
+	return true
+}
+
+// spill moves memory buffer content to temp file
+func (b *HybridBuffer) spill() error {
+	file, err := os.CreateTemp(b.dir, b.pattern)
+	if err != nil {
+		return err
+	}
+	if _, err = file.WriteAt(b.buf[:b.s], 0); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	b.file = file
+	b.buf = nil
+	b.release()
+	if b.budget != nil {
+		b.budget.spills.Add(1)
+		b.budget.spilledBytes.Add(b.s)
+	}
+	return nil
+}
+
+func (b *HybridBuffer) reserve(n int64) bool {
+	if b.budget != nil && !b.budget.reserve(n) {
+		return false
+	}
+	b.reserved += n
+	return true
+}
+
+func (b *HybridBuffer) release() {
+	if b.budget != nil && b.reserved > 0 {
+		b.budget.release(b.reserved)
+	}
+	b.reserved = 0
+}
@@ -148,3 +148,83 @@ func TestMemoryBuffer_Seek(t *testing.T) {
 		}
 	}
 }
+
+func TestSeekStream_HybridBuffer(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		budget := NewMemoryBudget(0)
+		buffer := NewHybridBuffer(10, 100, budget, "", "imagor-")
+		doSeekStreamTests(t, buffer)
+		assert.False(t, buffer.Spilled())
+		assert.Equal(t, int64(0), budget.Used())
+		assert.Equal(t, int64(0), budget.Spills())
+	})
+	t.Run("unknown size", func(t *testing.T) {
+		buffer := NewHybridBuffer(0, 100, nil, "", "imagor-")
+		doSeekStreamTests(t, buffer)
+		assert.False(t, buffer.Spilled())
+	})
+	t.Run("spill threshold", func(t *testing.T) {
+		budget := NewMemoryBudget(0)
+		buffer := NewHybridBuffer(0, 4, budget, "", "imagor-")
+		doSeekStreamTests(t, buffer)
+		assert.Equal(t, int64(1), budget.Spills())
+		assert.Equal(t, int64(10), budget.SpilledBytes())
+		assert.Equal(t, int64(0), budget.Used())
+	})
+	t.Run("spill budget", func(t *testing.T) {
+		budget := NewMemoryBudget(6)
+		buffer := NewHybridBuffer(0, 100, budget, "", "imagor-")
+		doSeekStreamTests(t, buffer)
+		assert.Equal(t, int64(1), budget.Spills())
+		assert.Equal(t, int64(0), budget.Used())
+	})
+}
+
+func TestHybridBuffer_MemoryBudget(t *testing.T) {
+	budget := NewMemoryBudget(10)
+	a := NewHybridBuffer(8, 100, budget, "", "imagor-")
+	assert.Equal(t, int64(8), budget.Used())
+	n, err := a.Write([]byte("01234567"))
+	assert.Equal(t, 8, n)
+	assert.NoError(t, err)
+	assert.False(t, a.Spilled())
+
+	// exceeds shared budget, spills to temp file
+	b := NewHybridBuffer(8, 100, budget, "", "imagor-")
+	assert.Equal(t, int64(8), budget.Used())
+	n, err = b.Write([]byte("abcdefgh"))
+	assert.Equal(t, 8, n)
+	assert.NoError(t, err)
+	assert.True(t, b.Spilled())
+	assert.Equal(t, int64(1), budget.Spills())
+
+	_, _ = b.Seek(2, io.SeekStart)
+	buf := make([]byte, 4)
+	n, err = b.Read(buf)
+	assert.Equal(t, 4, n)
+	assert.NoError(t, err)
+	assert.Equal(t, "cdef", string(buf))
+
+	// grows beyond budget, spills with memory content retained
+	n, err = a.Write([]byte("89ab"))
+	assert.Equal(t, 4, n)
+	assert.NoError(t, err)
+	assert.True(t, a.Spilled())
+	assert.Equal(t, int64(0), budget.Used())
+	assert.Equal(t, int64(2), budget.Spills())
+	_, _ = a.Seek(0, io.SeekStart)
+	res, err := io.ReadAll(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789ab", string(res))
+
+	a.Clear()
+	b.Clear()
+	assert.Equal(t, int64(0), budget.Used())
+
+	c := NewHybridBuffer(0, 100, budget, "", "imagor-")
+	_, _ = c.Write([]byte("0123"))
+	assert.False(t, c.Spilled())
+	assert.Equal(t, int64(4), budget.Used())
+	c.Clear()
+	assert.Equal(t, int64(0), budget.Used())
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,6 +26,7 @@ type FileStorage struct {
 	SaveErrIfExists bool
 	SafeChars       string
 	Expiration      time.Duration
+	Mmap            bool
 
 	safeChars imagorpath.SafeChars
 }
@@ -65,12 +67,16 @@ func (s *FileStorage) Get(_ *http.Request, image string) (*imagor.Blob, error) {
 	if !ok {
 		return nil, imagor.ErrInvalid
 	}
-	return imagor.NewBlobFromFile(image, func(stat os.FileInfo) error {
+	check := func(stat os.FileInfo) error {
 		if s.Expiration > 0 && time.Now().Sub(stat.ModTime()) > s.Expiration {
 			return imagor.ErrExpired
 		}
 		return nil
-	}), nil
+	}
+	if s.Mmap {
+		return imagor.NewBlobFromFileMmap(image, check), nil
+	}
+	return imagor.NewBlobFromFile(image, check), nil
 }
 
 // Put implements imagor.Storage interface
@@ -89,6 +95,9 @@ func (s *FileStorage) Put(_ context.Context, image string, blob *imagor.Blob) (e
 	defer func() {
 		_ = reader.Close()
 	}()
+	if s.Mmap && !s.SaveErrIfExists {
+		return s.putAtomic(image, reader)
+	}
 	flag := os.O_RDWR | os.O_CREATE | os.O_TRUNC
 	if s.SaveErrIfExists {
 		flag = os.O_RDWR | os.O_CREATE | os.O_EXCL
@@ -112,6 +121,33 @@ func (s *FileStorage) Put(_ context.Context, image string, blob *imagor.Blob) (e
 	return
 }
 
+// putAtomic writes to temp file and renames over the target,
+// so that existing memory mappings of the file are not truncated
+func (s *FileStorage) putAtomic(image string, reader io.Reader) (err error) {
+	tmp := filepath.Join(filepath.Dir(image),
+		"."+filepath.Base(image)+"."+strconv.FormatInt(time.Now().UnixNano(), 36))
+	w, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_EXCL, s.WritePermission)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = w.Close()
+		if err != nil {
+			_ = os.Remove(w.Name())
+		}
+	}()
+	if _, err = io.Copy(w, reader); err != nil {
+		return
+	}
+	if err = w.Sync(); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+	return os.Rename(w.Name(), image)
+}
+
 // Delete implements imagor.Storage interface
 func (s *FileStorage) Delete(_ context.Context, image string) error {
 	image, ok := s.Path(image)
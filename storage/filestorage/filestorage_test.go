@@ -5,8 +5,10 @@ import (
 	"github.com/kumparan/imagor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 	"time"
@@ -196,6 +198,43 @@ func TestFileStorage_Load_Save(t *testing.T) {
 		assert.Equal(t, "bar", string(buf))
 	})
 
+	t.Run("mmap", func(t *testing.T) {
+		s := New(dir, WithMmap(true))
+		_, err := checkBlob(s.Get(r, "/foo/mmap/asdf"))
+		assert.Equal(t, imagor.ErrNotFound, err)
+
+		require.NoError(t, s.Put(ctx, "/foo/mmap/asdf", imagor.NewBlobFromBytes([]byte("bar"))))
+		b, err := checkBlob(s.Get(r, "/foo/mmap/asdf"))
+		require.NoError(t, err)
+		reader, size, err := b.NewReader()
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), size)
+
+		// overwrite while mapped, existing reader keeps the old content
+		require.NoError(t, s.Put(ctx, "/foo/mmap/asdf", imagor.NewBlobFromBytes([]byte("boooo"))))
+		buf, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "bar", string(buf))
+		require.NoError(t, reader.Close())
+
+		b, err = checkBlob(s.Get(r, "/foo/mmap/asdf"))
+		require.NoError(t, err)
+		buf, err = b.ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, "boooo", string(buf))
+
+		entries, err := os.ReadDir(filepath.Join(dir, "foo/mmap"))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temp file cleaned up")
+
+		require.NoError(t, s.Put(ctx, "/foo/mmap/empty", imagor.NewBlobFromBytes([]byte{})))
+		b, err = checkBlob(s.Get(r, "/foo/mmap/empty"))
+		require.NoError(t, err)
+		buf, err = b.ReadAll()
+		require.NoError(t, err)
+		assert.Empty(t, buf)
+	})
+
 	t.Run("expiration", func(t *testing.T) {
 		s := New(dir, WithExpiration(time.Millisecond*10))
 		var err error
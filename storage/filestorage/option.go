@@ -79,3 +79,11 @@ func WithExpiration(exp time.Duration) Option {
 		}
 	}
 }
+
+// WithMmap with memory-mapped file reads option.
+// Files are written atomically by rename so that existing mappings remain valid
+func WithMmap(enabled bool) Option {
+	return func(h *FileStorage) {
+		h.Mmap = enabled
+	}
+}
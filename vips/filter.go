@@ -3,8 +3,10 @@ package vips
 import (
 	"context"
 	"fmt"
+	"html"
 	"image/color"
 	"math"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -83,54 +85,138 @@ func (v *Processor) watermark(ctx context.Context, img *Image, load imagor.LoadF
 	}
 	// x y
 	if ln >= 3 {
-		if args[1] == "center" {
-			x = (img.Width() - overlay.Width()) / 2
-		} else if args[1] == imagorpath.HAlignLeft {
-			x = 0
-		} else if args[1] == imagorpath.HAlignRight {
-			x = img.Width() - overlay.Width()
-		} else if args[1] == "repeat" {
-			x = 0
-			across = img.Width()/overlay.Width() + 1
-		} else if strings.HasPrefix(strings.TrimPrefix(args[1], "-"), "0.") {
-			pec, _ := strconv.ParseFloat(args[1], 64)
-			x = int(pec * float64(img.Width()))
-		} else if strings.HasSuffix(args[1], "p") {
-			x, _ = strconv.Atoi(strings.TrimSuffix(args[1], "p"))
-			x = x * img.Width() / 100
-		} else {
-			x, _ = strconv.Atoi(args[1])
+		x, across = getPosition(args[1], img.Width(), overlay.Width(),
+			imagorpath.HAlignLeft, imagorpath.HAlignRight)
+		y, down = getPosition(args[2], img.PageHeight(), overlay.PageHeight(),
+			imagorpath.VAlignTop, imagorpath.VAlignBottom)
+	}
+	if across*down > 1 {
+		if err = overlay.Embed(0, 0, across*w, down*h, ExtendRepeat); err != nil {
+			return
 		}
-		if args[2] == "center" {
-			y = (img.PageHeight() - overlay.PageHeight()) / 2
-		} else if args[2] == imagorpath.VAlignTop {
-			y = 0
-		} else if args[2] == imagorpath.VAlignBottom {
-			y = img.PageHeight() - overlay.PageHeight()
-		} else if args[2] == "repeat" {
-			y = 0
-			down = img.PageHeight()/overlay.PageHeight() + 1
-		} else if strings.HasPrefix(strings.TrimPrefix(args[2], "-"), "0.") {
-			pec, _ := strconv.ParseFloat(args[2], 64)
-			y = int(pec * float64(img.PageHeight()))
-		} else if strings.HasSuffix(args[2], "p") {
-			y, _ = strconv.Atoi(strings.TrimSuffix(args[2], "p"))
-			y = y * img.PageHeight() / 100
-		} else {
-			y, _ = strconv.Atoi(args[2])
+	}
+	if err = overlay.EmbedBackgroundRGBA(
+		x, y, img.Width(), img.PageHeight(), &ColorRGBA{},
+	); err != nil {
+		return
+	}
+	if n := img.Height() / img.PageHeight(); n > overlayN {
+		cnt := n / overlayN
+		if n%overlayN > 0 {
+			cnt++
 		}
-		if x < 0 {
-			x += img.Width() - overlay.Width()
+		if err = overlay.Replicate(1, cnt); err != nil {
+			return
 		}
-		if y < 0 {
-			y += img.PageHeight() - overlay.PageHeight()
+	}
+	if err = img.Composite(overlay, BlendModeOver, 0, 0); err != nil {
+		return
+	}
+	return
+}
+
+// layer composites image on top of the image with blend mode,
+// positioned like watermark with optional size and rotation:
+// layer(image[,x[,y[,alpha[,blend[,width[,height[,angle]]]]]]])
+func (v *Processor) layer(ctx context.Context, img *Image, load imagor.LoadFunc, args ...string) (err error) {
+	ln := len(args)
+	if ln < 1 {
+		return
+	}
+	image := args[0]
+	if unescape, e := url.QueryUnescape(args[0]); e == nil {
+		image = unescape
+	}
+	var mode = BlendModeOver
+	if ln >= 5 && args[4] != "" {
+		var ok bool
+		if mode, ok = blendModes[strings.ToLower(args[4])]; !ok {
+			return imagor.NewError("invalid blend mode "+args[4], http.StatusBadRequest)
+		}
+	}
+	var blob *imagor.Blob
+	if blob, err = load(image); err != nil {
+		return
+	}
+	var n = 1
+	if isAnimated(img) {
+		n = -1
+	}
+	var w, h int
+	if ln >= 6 {
+		w = getSize(args[5], img.Width())
+	}
+	if ln >= 7 {
+		h = getSize(args[6], img.PageHeight())
+	}
+	var overlay *Image
+	if w > 0 || h > 0 {
+		if w == 0 {
+			w = v.MaxWidth
+		}
+		if h == 0 {
+			h = v.MaxHeight
+		}
+		if overlay, err = v.NewThumbnail(
+			ctx, blob, w, h, InterestingNone, SizeBoth, n, 1, 0,
+		); err != nil {
+			return
+		}
+	} else {
+		if overlay, err = v.NewThumbnail(
+			ctx, blob, v.MaxWidth, v.MaxHeight, InterestingNone, SizeDown, n, 1, 0,
+		); err != nil {
+			return
+		}
+	}
+	contextDefer(ctx, overlay.Close)
+	if overlay.Bands() < 3 {
+		if err = overlay.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	if err = overlay.AddAlpha(); err != nil {
+		return
+	}
+	if ln >= 8 {
+		if angle, _ := strconv.ParseFloat(args[7], 64); math.Mod(angle, 360) != 0 {
+			if err = overlay.RotateAngle(angle, &ColorRGBA{}); err != nil {
+				return
+			}
+		}
+	}
+	// alpha
+	if ln >= 4 {
+		alpha, _ := strconv.ParseFloat(args[3], 64)
+		alpha = 1 - alpha/100
+		if alpha != 1 {
+			if err = overlay.Linear([]float64{1, 1, 1, alpha}, []float64{0, 0, 0, 0}); err != nil {
+				return
+			}
 		}
 	}
+	var x, y int
+	var across, down = 1, 1
+	if ln >= 2 {
+		x, across = getPosition(args[1], img.Width(), overlay.Width(),
+			imagorpath.HAlignLeft, imagorpath.HAlignRight)
+	}
+	if ln >= 3 {
+		y, down = getPosition(args[2], img.PageHeight(), overlay.PageHeight(),
+			imagorpath.VAlignTop, imagorpath.VAlignBottom)
+	}
 	if across*down > 1 {
-		if err = overlay.Embed(0, 0, across*w, down*h, ExtendRepeat); err != nil {
+		if err = overlay.Embed(0, 0, across*overlay.Width(), down*overlay.PageHeight(), ExtendRepeat); err != nil {
 			return
 		}
 	}
+	return compositeOverlay(img, overlay, x, y, mode)
+}
+
+// compositeOverlay composites overlay with alpha at x, y of each frame of the image,
+// replicating overlay frames across animated image
+func compositeOverlay(img, overlay *Image, x, y int, mode BlendMode) (err error) {
+	var overlayN = overlay.Height() / overlay.PageHeight()
 	if err = overlay.EmbedBackgroundRGBA(
 		x, y, img.Width(), img.PageHeight(), &ColorRGBA{},
 	); err != nil {
@@ -145,12 +231,72 @@ func (v *Processor) watermark(ctx context.Context, img *Image, load imagor.LoadF
 			return
 		}
 	}
-	if err = img.Composite(overlay, BlendModeOver, 0, 0); err != nil {
-		return
+	if img.Bands() < 3 {
+		if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	return img.Composite(overlay, mode, 0, 0)
+}
+
+// blendModes blend modes by name supported by layer
+var blendModes = map[string]BlendMode{
+	"over":         BlendModeOver,
+	"add":          BlendModeAdd,
+	"saturate":     BlendModeSaturate,
+	"multiply":     BlendModeMultiply,
+	"screen":       BlendModeScreen,
+	"overlay":      BlendModeOverlay,
+	"darken":       BlendModeDarken,
+	"lighten":      BlendModeLighten,
+	"color_dodge":  BlendModeColorDodge,
+	"colour_dodge": BlendModeColorDodge,
+	"color_burn":   BlendModeColorBurn,
+	"colour_burn":  BlendModeColorBurn,
+	"hard_light":   BlendModeHardLight,
+	"soft_light":   BlendModeSoftLight,
+	"difference":   BlendModeDifference,
+	"exclusion":    BlendModeExclusion,
+}
+
+// getPosition returns offset of overlay within size by position argument,
+// either center, start or end keyword, repeat, ratio, percentage or pixels.
+// Negative offset counts from the end. Returns number of repeats
+func getPosition(arg string, size, overlaySize int, start, end string) (pos int, repeat int) {
+	repeat = 1
+	if arg == "center" {
+		pos = (size - overlaySize) / 2
+	} else if arg == start {
+		pos = 0
+	} else if arg == end {
+		pos = size - overlaySize
+	} else if arg == "repeat" {
+		return 0, size/overlaySize + 1
+	} else if strings.HasPrefix(strings.TrimPrefix(arg, "-"), "0.") {
+		pec, _ := strconv.ParseFloat(arg, 64)
+		pos = int(pec * float64(size))
+	} else if strings.HasSuffix(arg, "p") {
+		pos, _ = strconv.Atoi(strings.TrimSuffix(arg, "p"))
+		pos = pos * size / 100
+	} else {
+		pos, _ = strconv.Atoi(arg)
+	}
+	if pos < 0 {
+		pos += size - overlaySize
 	}
 	return
 }
 
+// getSize returns size in pixels, or percentage of total with p suffix. 0 if none
+func getSize(arg string, total int) int {
+	if strings.HasSuffix(arg, "p") {
+		n, _ := strconv.Atoi(strings.TrimSuffix(arg, "p"))
+		return max(n*total/100, 0)
+	}
+	n, _ := strconv.Atoi(arg)
+	return max(n, 0)
+}
+
 func setFrames(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
 	ln := len(args)
 	if ln == 0 {
@@ -201,12 +347,13 @@ func (v *Processor) fill(ctx context.Context, img *Image, w, h int, pLeft, pTop,
 		pRight = pBottom
 		pBottom = tmpPRight
 	}
+	args := strings.Split(colour, ",")
 	c := getColor(img, colour)
 	left := (w-img.Width())/2 + pLeft
 	top := (h-img.PageHeight())/2 + pTop
 	width := w + pLeft + pRight
 	height := h + pTop + pBottom
-	if colour != "blur" || (colour == "blur" && v.DisableBlur) || isAnimated(img) {
+	if args[0] != "blur" || v.DisableBlur {
 		// fill color
 		isTransparent := colour == "none" || colour == "transparent"
 		if img.HasAlpha() && !isTransparent {
@@ -240,22 +387,44 @@ func (v *Processor) fill(ctx context.Context, img *Image, w, h int, pLeft, pTop,
 			}
 		}
 	} else {
-		// fill blur
+		// fill blur, sigma and brightness
+		var sigma float64 = 50
+		var bright float64
+		if len(args) > 1 {
+			if f, e := strconv.ParseFloat(args[1], 64); e == nil && f > 0 {
+				sigma = f
+			}
+		}
+		if len(args) > 2 {
+			bright, _ = strconv.ParseFloat(args[2], 64)
+			bright = bright * 255 / 100
+		}
 		var cp *Image
 		if cp, err = img.Copy(); err != nil {
 			return
 		}
 		contextDefer(ctx, cp.Close)
-		if err = img.ThumbnailWithSize(
-			width, height, InterestingNone, SizeForce,
-		); err != nil {
+		if err = img.BlurBackground(width, height, sigma, bright); err != nil {
 			return
 		}
-		if err = img.GaussianBlur(50); err != nil {
+		// embed frames into the target dimensions for composite of each frame
+		if cp.Bands() < 3 {
+			if err = cp.ToColorSpace(InterpretationSRGB); err != nil {
+				return
+			}
+		}
+		if err = cp.AddAlpha(); err != nil {
 			return
 		}
-		if err = img.Composite(
-			cp, BlendModeOver, left, top); err != nil {
+		if err = cp.EmbedBackgroundRGBA(left, top, width, height, &ColorRGBA{}); err != nil {
+			return
+		}
+		if img.Bands() < 3 {
+			if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+				return
+			}
+		}
+		if err = img.Composite(cp, BlendModeOver, 0, 0); err != nil {
 			return
 		}
 	}
@@ -311,6 +480,205 @@ func roundCorner(ctx context.Context, img *Image, _ imagor.LoadFunc, args ...str
 	return nil
 }
 
+func border(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	if len(args) == 0 {
+		return
+	}
+	width, _ := strconv.Atoi(args[0])
+	if width <= 0 {
+		return
+	}
+	colour := "000000"
+	if len(args) > 1 && args[1] != "" {
+		colour = args[1]
+	}
+	if img.Bands() < 3 {
+		if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	w := img.Width() + width*2
+	h := img.PageHeight() + width*2
+	if colour == "none" || colour == "transparent" {
+		if err = img.AddAlpha(); err != nil {
+			return
+		}
+		return img.EmbedBackgroundRGBA(width, width, w, h, &ColorRGBA{})
+	}
+	c := getColor(img, colour)
+	if img.HasAlpha() {
+		return img.EmbedBackgroundRGBA(width, width, w, h, &ColorRGBA{R: c.R, G: c.G, B: c.B, A: 255})
+	}
+	return img.EmbedBackground(width, width, w, h, c)
+}
+
+func shadow(ctx context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	ln := len(args)
+	if ln < 2 {
+		return
+	}
+	var (
+		x, _    = strconv.Atoi(args[0])
+		y, _    = strconv.Atoi(args[1])
+		sigma   float64
+		c       = &Color{}
+		opacity = 0.5
+	)
+	if ln > 2 {
+		sigma, _ = strconv.ParseFloat(args[2], 64)
+		sigma = math.Min(math.Max(sigma, 0), 100)
+	}
+	if ln > 3 && args[3] != "" {
+		c = getColor(img, args[3])
+	}
+	if ln > 4 {
+		if f, e := strconv.ParseFloat(args[4], 64); e == nil {
+			opacity = math.Min(math.Max(f, 0), 100) / 100
+		}
+	}
+	if img.Bands() < 3 {
+		if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	if err = img.AddAlpha(); err != nil {
+		return
+	}
+	// expand canvas for the shadow offset and blur extent,
+	// which also keeps the blur within each frame of animated image
+	margin := int(math.Ceil(sigma * 3))
+	left, top := max(margin-x, 0), max(margin-y, 0)
+	w := img.Width() + left + max(margin+x, 0)
+	h := img.PageHeight() + top + max(margin+y, 0)
+	if err = img.EmbedBackgroundRGBA(left, top, w, h, &ColorRGBA{}); err != nil {
+		return
+	}
+	var shade *Image
+	if shade, err = img.Copy(); err != nil {
+		return
+	}
+	contextDefer(ctx, shade.Close)
+	if err = shade.Linear(
+		[]float64{0, 0, 0, opacity}, []float64{float64(c.R), float64(c.G), float64(c.B), 0},
+	); err != nil {
+		return
+	}
+	if err = shade.EmbedBackgroundRGBA(x, y, w, h, &ColorRGBA{}); err != nil {
+		return
+	}
+	if sigma > 0 {
+		if err = shade.GaussianBlur(sigma); err != nil {
+			return
+		}
+	}
+	return img.Composite(shade, BlendModeDestOver, 0, 0)
+}
+
+func vignette(ctx context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	if len(args) == 0 {
+		return
+	}
+	strength, _ := strconv.ParseFloat(args[0], 64)
+	strength = math.Min(strength, 100) / 100
+	if strength <= 0 {
+		return
+	}
+	var mask *Image
+	var w = img.Width()
+	var h = img.PageHeight()
+	if mask, err = LoadImageFromBuffer([]byte(fmt.Sprintf(`
+		<svg width="%d" height="%d" viewBox="0 0 %d %d">
+			<radialGradient id="v" cx="50%%" cy="50%%" r="75%%">
+				<stop offset="40%%" stop-color="#000" stop-opacity="0"/>
+				<stop offset="100%%" stop-color="#000" stop-opacity="%g"/>
+			</radialGradient>
+			<rect x="0" y="0" width="%d" height="%d" fill="url(#v)"/>
+		</svg>
+	`, w, h, w, h, strength, w, h)), nil); err != nil {
+		return
+	}
+	contextDefer(ctx, mask.Close)
+	if n := img.Height() / img.PageHeight(); n > 1 {
+		if err = mask.Replicate(1, n); err != nil {
+			return
+		}
+	}
+	if img.Bands() < 3 {
+		if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	return img.Composite(mask, BlendModeAtop, 0, 0)
+}
+
+func (v *Processor) mask(ctx context.Context, img *Image, load imagor.LoadFunc, args ...string) (err error) {
+	ln := len(args)
+	if ln == 0 {
+		return
+	}
+	name := args[0]
+	if unescape, e := url.QueryUnescape(args[0]); e == nil {
+		name = unescape
+	}
+	invert := ln > 1 && (args[1] == "invert" || (args[1] != "" && parseEnabled(args[1])))
+	var m *Image
+	w, h := img.Width(), img.PageHeight()
+	if svg := maskShape(name, w, h); svg != "" {
+		if m, err = LoadImageFromBuffer([]byte(svg), nil); err != nil {
+			return
+		}
+	} else {
+		var blob *imagor.Blob
+		if blob, err = load(name); err != nil {
+			return
+		}
+		// first frame scaled to fit within frame dimensions
+		if m, err = v.NewThumbnail(ctx, blob, w, h, InterestingNone, SizeBoth, 1, 1, 0); err != nil {
+			return
+		}
+	}
+	contextDefer(ctx, m.Close)
+	if m.Interpretation() != InterpretationSRGB {
+		if err = m.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	return img.Mask(m, invert)
+}
+
+// maskShape returns SVG of built-in mask shape within dimensions,
+// or empty if not a shape
+func maskShape(name string, w, h int) string {
+	var shape string
+	switch {
+	case name == "circle":
+		shape = fmt.Sprintf(`<circle cx="%g" cy="%g" r="%g" fill="#fff"/>`,
+			float64(w)/2, float64(h)/2, float64(min(w, h))/2)
+	case name == "ellipse":
+		shape = fmt.Sprintf(`<ellipse cx="%g" cy="%g" rx="%g" ry="%g" fill="#fff"/>`,
+			float64(w)/2, float64(h)/2, float64(w)/2, float64(h)/2)
+	case name == "hexagon":
+		// regular pointy top hexagon centered
+		r := float64(min(w, h)) / 2
+		points := make([]string, 6)
+		for i := range points {
+			rad := (float64(i)*60 - 90) * math.Pi / 180
+			points[i] = fmt.Sprintf("%g,%g", float64(w)/2+r*math.Cos(rad), float64(h)/2+r*math.Sin(rad))
+		}
+		shape = fmt.Sprintf(`<polygon points="%s" fill="#fff"/>`, strings.Join(points, " "))
+	case strings.HasPrefix(name, "path:"):
+		// path data in 0 to 100 coordinates stretched to dimensions
+		return fmt.Sprintf(`
+			<svg width="%d" height="%d" viewBox="0 0 100 100" preserveAspectRatio="none">
+				<path d="%s" fill="#fff"/>
+			</svg>
+		`, w, h, html.EscapeString(strings.TrimPrefix(name, "path:")))
+	default:
+		return ""
+	}
+	return fmt.Sprintf(`<svg width="%d" height="%d" viewBox="0 0 %d %d">%s</svg>`, w, h, w, h, shape)
+}
+
 func label(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
 	ln := len(args)
 	if ln == 0 {
@@ -433,18 +801,124 @@ func rotate(ctx context.Context, img *Image, _ imagor.LoadFunc, args ...string)
 	if len(args) == 0 {
 		return
 	}
-	if angle, _ := strconv.Atoi(args[0]); angle > 0 {
-		switch angle {
-		case 90, 270:
-			setRotate90(ctx)
+	if angle, e := strconv.Atoi(args[0]); e == nil && angle%90 == 0 && len(args) == 1 {
+		if angle > 0 {
+			switch angle {
+			case 90, 270:
+				setRotate90(ctx)
+			}
+			if err = img.Rotate(getAngle(angle)); err != nil {
+				return err
+			}
 		}
-		if err = img.Rotate(getAngle(angle)); err != nil {
-			return err
+		return
+	}
+	// arbitrary angle with background color or crop
+	angle, _ := strconv.ParseFloat(args[0], 64)
+	angle = math.Mod(angle, 360)
+	if angle == 0 {
+		return
+	}
+	var colour string
+	if len(args) > 1 {
+		colour = args[1]
+	}
+	crop := colour == "crop"
+	if crop {
+		colour = ""
+	}
+	w, h := img.Width(), img.PageHeight()
+	var bg *ColorRGBA
+	if bg, err = getBackground(img, colour); err != nil {
+		return
+	}
+	if err = img.RotateAngle(angle, bg); err != nil {
+		return
+	}
+	if crop {
+		cw, ch := inscribedRect(float64(w), float64(h), angle)
+		width := int(math.Floor(cw))
+		height := int(math.Floor(ch))
+		if width > 0 && height > 0 && width <= img.Width() && height <= img.PageHeight() {
+			return img.ExtractArea((img.Width()-width)/2, (img.PageHeight()-height)/2, width, height)
 		}
 	}
 	return
 }
 
+func affine(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	if len(args) < 4 {
+		return
+	}
+	var m [4]float64
+	for i := range m {
+		if m[i], err = strconv.ParseFloat(args[i], 64); err != nil {
+			return imagor.NewError("invalid affine matrix", http.StatusBadRequest)
+		}
+	}
+	if m[0]*m[3]-m[1]*m[2] == 0 {
+		return imagor.NewError("invalid affine matrix", http.StatusBadRequest)
+	}
+	var colour string
+	if len(args) > 4 {
+		colour = args[4]
+	}
+	var bg *ColorRGBA
+	if bg, err = getBackground(img, colour); err != nil {
+		return
+	}
+	return img.Affine(m[0], m[1], m[2], m[3], bg)
+}
+
+func perspective(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	if len(args) < 8 {
+		return
+	}
+	var pts [8]float64
+	isRatio := true
+	for i := range pts {
+		if pts[i], err = strconv.ParseFloat(args[i], 64); err != nil {
+			return imagor.NewError("invalid perspective points", http.StatusBadRequest)
+		}
+		if pts[i] > 1 {
+			isRatio = false
+		}
+	}
+	if isRatio {
+		// ratios of the image dimensions
+		for i := range pts {
+			if i%2 == 0 {
+				pts[i] *= float64(img.Width())
+			} else {
+				pts[i] *= float64(img.PageHeight())
+			}
+		}
+	}
+	// output dimensions from the longer edges of the quadrilateral
+	width := int(math.Round(math.Max(
+		math.Hypot(pts[2]-pts[0], pts[3]-pts[1]), math.Hypot(pts[4]-pts[6], pts[5]-pts[7]))))
+	height := int(math.Round(math.Max(
+		math.Hypot(pts[6]-pts[0], pts[7]-pts[1]), math.Hypot(pts[4]-pts[2], pts[5]-pts[3]))))
+	if width <= 0 || height <= 0 {
+		return imagor.NewError("invalid perspective points", http.StatusBadRequest)
+	}
+	matrix, ok := homography([8]float64{
+		0, 0, float64(width), 0, float64(width), float64(height), 0, float64(height),
+	}, pts)
+	if !ok {
+		return imagor.NewError("invalid perspective points", http.StatusBadRequest)
+	}
+	var colour string
+	if len(args) > 8 {
+		colour = args[8]
+	}
+	var bg *ColorRGBA
+	if bg, err = getBackground(img, colour); err != nil {
+		return
+	}
+	return img.Perspective(matrix, width, height, bg)
+}
+
 func getAngle(angle int) Angle {
 	switch angle {
 	case 90:
@@ -458,6 +932,16 @@ func getAngle(angle int) Angle {
 	}
 }
 
+func pixelate(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	if len(args) == 0 {
+		return
+	}
+	if size, _ := strconv.Atoi(args[0]); size > 1 {
+		return img.Pixelate(size)
+	}
+	return
+}
+
 func proportion(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
 	if len(args) == 0 {
 		return
@@ -547,6 +1031,253 @@ func modulate(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string)
 	return img.Modulate(b, s, h)
 }
 
+func gamma(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	if len(args) == 0 {
+		return
+	}
+	g, _ := strconv.ParseFloat(args[0], 64)
+	if g <= 0 || g == 1 {
+		return
+	}
+	return img.Gamma(math.Min(math.Max(g, 0.01), 100))
+}
+
+func levels(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	ln := len(args)
+	if ln < 2 {
+		return
+	}
+	black, _ := strconv.ParseFloat(args[0], 64)
+	white, _ := strconv.ParseFloat(args[1], 64)
+	black = math.Min(math.Max(black, 0), 255)
+	white = math.Min(math.Max(white, 0), 255)
+	var g float64 = 1
+	if ln > 2 {
+		if f, e := strconv.ParseFloat(args[2], 64); e == nil && f > 0 {
+			g = f
+		}
+	}
+	if white <= black {
+		return imagor.NewError("levels white must be greater than black", http.StatusBadRequest)
+	}
+	if black == 0 && white == 255 && g == 1 {
+		return
+	}
+	return img.MapLUT(levelsLUT(img.LUTSize(), black, white, g))
+}
+
+func curves(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	var points [][2]float64
+	for _, arg := range args {
+		if arg == "" {
+			continue
+		}
+		xy := strings.Split(arg, ":")
+		if len(xy) != 2 {
+			return imagor.NewError("invalid curves point "+arg, http.StatusBadRequest)
+		}
+		x, e1 := strconv.ParseFloat(xy[0], 64)
+		y, e2 := strconv.ParseFloat(xy[1], 64)
+		if e1 != nil || e2 != nil || x < 0 || x > 255 || y < 0 || y > 255 {
+			return imagor.NewError("invalid curves point "+arg, http.StatusBadRequest)
+		}
+		points = append(points, [2]float64{x, y})
+	}
+	if len(points) < 2 {
+		return
+	}
+	return img.MapLUT(curvesLUT(img.LUTSize(), points))
+}
+
+func normalize(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	var clip float64 = 1
+	if len(args) > 0 && args[0] != "" {
+		clip, _ = strconv.ParseFloat(args[0], 64)
+		clip = math.Min(math.Max(clip, 0), 49)
+	}
+	return img.Normalize(clip)
+}
+
+func equalize(_ context.Context, img *Image, _ imagor.LoadFunc, _ ...string) (err error) {
+	return img.Equalize()
+}
+
+func sepia(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	var amount float64 = 1
+	if len(args) > 0 && args[0] != "" {
+		amount, _ = strconv.ParseFloat(args[0], 64)
+		amount = math.Min(math.Max(amount, 0), 100) / 100
+	}
+	if amount == 0 {
+		return
+	}
+	if img.Bands() < 3 {
+		if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	// sepia matrix interpolated with identity by amount
+	sepiaMatrix := [9]float64{
+		0.393, 0.769, 0.189,
+		0.349, 0.686, 0.168,
+		0.272, 0.534, 0.131,
+	}
+	var matrix [9]float64
+	for i := range matrix {
+		var identity float64
+		if i%4 == 0 {
+			identity = 1
+		}
+		matrix[i] = identity + (sepiaMatrix[i]-identity)*amount
+	}
+	return img.Recomb(matrix)
+}
+
+func duotone(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	if len(args) < 2 {
+		return
+	}
+	return img.Duotone(getColor(img, args[0]), getColor(img, args[1]))
+}
+
+func colorize(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	ln := len(args)
+	if ln == 0 {
+		return
+	}
+	c := getColor(img, args[0])
+	var amount float64 = 0.5
+	if ln > 1 && args[1] != "" {
+		amount, _ = strconv.ParseFloat(args[1], 64)
+		amount = math.Min(math.Max(amount, 0), 100) / 100
+	}
+	if amount == 0 {
+		return
+	}
+	if img.Bands() < 3 {
+		if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	// blend towards color by amount
+	scale := float64(img.LUTSize()-1) / 255
+	return linearRGB(img,
+		[]float64{1 - amount, 1 - amount, 1 - amount},
+		[]float64{float64(c.R) * scale * amount, float64(c.G) * scale * amount, float64(c.B) * scale * amount},
+	)
+}
+
+func gradient(ctx context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	ln := len(args)
+	if ln < 3 {
+		return
+	}
+	angle, _ := strconv.ParseFloat(args[0], 64)
+	var opacity float64 = 1
+	if ln > 3 && args[3] != "" {
+		opacity, _ = strconv.ParseFloat(args[3], 64)
+		opacity = math.Min(math.Max(opacity, 0), 100) / 100
+	}
+	if opacity == 0 {
+		return
+	}
+	stop := func(offset int, colour string) string {
+		if colour == "none" || colour == "transparent" {
+			return fmt.Sprintf(`<stop offset="%d%%" stop-color="#000" stop-opacity="0"/>`, offset)
+		}
+		c := getColor(img, colour)
+		return fmt.Sprintf(`<stop offset="%d%%" stop-color="#%02x%02x%02x"/>`, offset, c.R, c.G, c.B)
+	}
+	// gradient line through center by angle clockwise from left to right, spanning the frame
+	w, h := img.Width(), img.PageHeight()
+	rad := angle * math.Pi / 180
+	cos, sin := math.Cos(rad), math.Sin(rad)
+	l := (math.Abs(float64(w)*cos) + math.Abs(float64(h)*sin)) / 2
+	cx, cy := float64(w)/2, float64(h)/2
+	var overlay *Image
+	if overlay, err = LoadImageFromBuffer([]byte(fmt.Sprintf(`
+		<svg width="%d" height="%d" viewBox="0 0 %d %d">
+			<linearGradient id="g" gradientUnits="userSpaceOnUse" x1="%g" y1="%g" x2="%g" y2="%g">
+				%s
+				%s
+			</linearGradient>
+			<rect x="0" y="0" width="%d" height="%d" fill="url(#g)" opacity="%g"/>
+		</svg>
+	`, w, h, w, h, cx-cos*l, cy-sin*l, cx+cos*l, cy+sin*l,
+		stop(0, args[1]), stop(100, args[2]), w, h, opacity)), nil); err != nil {
+		return
+	}
+	contextDefer(ctx, overlay.Close)
+	if n := img.Height() / img.PageHeight(); n > 1 {
+		if err = overlay.Replicate(1, n); err != nil {
+			return
+		}
+	}
+	if img.Bands() < 3 {
+		if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	return img.Composite(overlay, BlendModeAtop, 0, 0)
+}
+
+func transparent(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	ln := len(args)
+	if ln == 0 {
+		return
+	}
+	if img.Interpretation() != InterpretationSRGB {
+		if err = img.ToColorSpace(InterpretationSRGB); err != nil {
+			return
+		}
+	}
+	c := getColor(img, args[0])
+	var tolerance, feather float64
+	if ln > 1 {
+		tolerance, _ = strconv.ParseFloat(args[1], 64)
+		tolerance = math.Min(math.Max(tolerance, 0), 100)
+	}
+	if ln > 2 {
+		feather, _ = strconv.ParseFloat(args[2], 64)
+		feather = math.Min(math.Max(feather, 0), 100)
+	}
+	flood := ln > 3 && args[3] == "flood"
+	// percentage of the max euclidean distance of RGB
+	maxDistance := 255 * math.Sqrt(3)
+	return img.Transparent(c, tolerance/100*maxDistance, feather/100*maxDistance, flood)
+}
+
+func convolution(_ context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
+	if isAnimated(img) {
+		// skip animation support
+		return
+	}
+	ln := len(args)
+	if ln < 2 {
+		return
+	}
+	columns, _ := strconv.Atoi(args[1])
+	items := strings.Split(args[0], ";")
+	if columns <= 0 || len(items)%columns != 0 {
+		return imagor.NewError("invalid convolution matrix "+args[0], http.StatusBadRequest)
+	}
+	if columns > imagorpath.MaxConvolutionSize || len(items)/columns > imagorpath.MaxConvolutionSize {
+		return imagor.NewError(fmt.Sprintf("convolution matrix exceeds %dx%d",
+			imagorpath.MaxConvolutionSize, imagorpath.MaxConvolutionSize), http.StatusBadRequest)
+	}
+	matrix := make([]float64, len(items))
+	for i, item := range items {
+		if matrix[i], err = strconv.ParseFloat(strings.TrimSpace(item), 64); err != nil {
+			return imagor.NewError("invalid convolution matrix "+args[0], http.StatusBadRequest)
+		}
+	}
+	var normalize bool
+	if ln > 2 {
+		normalize, _ = strconv.ParseBool(args[2])
+	}
+	return img.Convolution(matrix, columns, normalize)
+}
+
 func blur(ctx context.Context, img *Image, _ imagor.LoadFunc, args ...string) (err error) {
 	if isAnimated(img) {
 		// skip animation support
@@ -666,6 +1397,27 @@ func getColor(img *Image, color string) *Color {
 	return vc
 }
 
+// getBackground returns background color for transforms,
+// adding alpha channel for transparent background, i.e. "none", "transparent" or unspecified with alpha
+func getBackground(img *Image, colour string) (*ColorRGBA, error) {
+	if img.Bands() < 3 {
+		if err := img.ToColorSpace(InterpretationSRGB); err != nil {
+			return nil, err
+		}
+	}
+	if colour == "" {
+		return &ColorRGBA{}, nil
+	}
+	if colour == "none" || colour == "transparent" {
+		if err := img.AddAlpha(); err != nil {
+			return nil, err
+		}
+		return &ColorRGBA{}, nil
+	}
+	c := getColor(img, colour)
+	return &ColorRGBA{R: c.R, G: c.G, B: c.B, A: 255}, nil
+}
+
 func parseHexColor(s string) (c color.RGBA, ok bool) {
 	c.A = 0xff
 	switch len(s) {
@@ -0,0 +1,158 @@
+package vips
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kumparan/imagor"
+	"github.com/kumparan/imagor/imagorpath"
+)
+
+var fontExts = map[string]bool{".ttf": true, ".otf": true, ".ttc": true}
+
+// fontCacheTTL duration a loaded font is cached by path before reloading,
+// so that font changed at the same path is picked up
+const fontCacheTTL = time.Hour
+
+// loadFont loads font file through the load function,
+// stored in FontDir as local file named by content digest for fontconfig,
+// and cached by path for fontCacheTTL up to MaxFontFiles least recently used files.
+//
+// Fontconfig keeps every font file registered for the process lifetime,
+// FontPrefix should be set to restrict fonts to a trusted set of files
+func (v *Processor) loadFont(load imagor.LoadFunc, image string) (string, error) {
+	if filename, ok := v.fonts.get(image); ok {
+		return filename, nil
+	}
+	ext := strings.ToLower(path.Ext(image))
+	if !fontExts[ext] {
+		return "", imagor.NewError("unsupported font "+image, http.StatusBadRequest)
+	}
+	if !(imagorpath.Policy{Prefix: v.FontPrefix}).AllowImage(image) {
+		return "", imagor.NewError(fmt.Sprintf("font %s not allowed", image), http.StatusForbidden)
+	}
+	blob, err := load(image)
+	if err != nil {
+		return "", err
+	}
+	buf, err := blob.ReadAll()
+	if err != nil {
+		return "", err
+	}
+	dir := v.fontDir()
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	digest := sha1.Sum(buf)
+	filename := filepath.Join(dir, hex.EncodeToString(digest[:])+ext)
+	v.fonts.Lock()
+	defer v.fonts.Unlock()
+	if _, err = os.Stat(filename); os.IsNotExist(err) {
+		// write to temp file then rename, so that concurrent readers never see partial font
+		tmp := filename + "." + strconv.FormatInt(time.Now().UnixNano(), 36)
+		if err = os.WriteFile(tmp, buf, 0644); err != nil {
+			return "", err
+		}
+		if err = os.Rename(tmp, filename); err != nil {
+			_ = os.Remove(tmp)
+			return "", err
+		}
+	} else if err != nil {
+		return "", err
+	}
+	v.fonts.put(image, filename)
+	return filename, nil
+}
+
+func (v *Processor) fontDir() string {
+	if v.FontDir != "" {
+		return v.FontDir
+	}
+	return filepath.Join(os.TempDir(), "imagor-fonts")
+}
+
+// fontCache least recently used font files by path.
+// Font file no longer referenced by any path is removed on eviction
+type fontCache struct {
+	sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type fontEntry struct {
+	image    string
+	filename string
+	expire   time.Time
+}
+
+func newFontCache(size int) *fontCache {
+	return &fontCache{size: size, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+func (c *fontCache) get(image string) (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.items[image]
+	if !ok {
+		return "", false
+	}
+	entry := e.Value.(*fontEntry)
+	if time.Now().After(entry.expire) {
+		return "", false
+	}
+	c.ll.MoveToFront(e)
+	return entry.filename, true
+}
+
+// put stores filename of image, caller must hold the lock
+func (c *fontCache) put(image, filename string) {
+	expire := time.Now().Add(fontCacheTTL)
+	if e, ok := c.items[image]; ok {
+		entry := e.Value.(*fontEntry)
+		prev := entry.filename
+		entry.filename, entry.expire = filename, expire
+		c.ll.MoveToFront(e)
+		c.release(prev)
+	} else {
+		c.items[image] = c.ll.PushFront(&fontEntry{image: image, filename: filename, expire: expire})
+	}
+	for c.size > 0 && c.ll.Len() > c.size {
+		e := c.ll.Back()
+		entry := e.Value.(*fontEntry)
+		c.ll.Remove(e)
+		delete(c.items, entry.image)
+		c.release(entry.filename)
+	}
+}
+
+// release removes font file if no longer referenced by any path
+func (c *fontCache) release(filename string) {
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		if e.Value.(*fontEntry).filename == filename {
+			return
+		}
+	}
+	_ = os.Remove(filename)
+}
+
+// purge removes all font files
+func (c *fontCache) purge() {
+	c.Lock()
+	defer c.Unlock()
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		_ = os.Remove(e.Value.(*fontEntry).filename)
+	}
+	c.ll.Init()
+	c.items = map[string]*list.Element{}
+}
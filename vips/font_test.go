@@ -0,0 +1,60 @@
+package vips
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kumparan/imagor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFont(t *testing.T) {
+	v := NewProcessor(WithFontDir(t.TempDir()), WithFontPrefix("fonts/"), WithMaxFontFiles(2))
+	fonts := map[string]string{
+		"fonts/a.ttf": "a", "fonts/b.ttf": "b", "fonts/c.otf": "c", "fonts/d.ttf": "a",
+	}
+	var loads int
+	load := func(image string) (*imagor.Blob, error) {
+		loads++
+		return imagor.NewBlobFromBytes([]byte(fonts[image])), nil
+	}
+
+	a, err := v.loadFont(load, "fonts/a.ttf")
+	assert.NoError(t, err)
+	d, err := v.loadFont(load, "fonts/d.ttf")
+	assert.NoError(t, err)
+	assert.Equal(t, a, d, "same content shares file")
+	_, err = v.loadFont(load, "fonts/a.ttf")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, loads)
+
+	b, err := v.loadFont(load, "fonts/b.ttf")
+	assert.NoError(t, err)
+	assert.FileExists(t, a, "still referenced by fonts/a.ttf")
+	_, err = v.loadFont(load, "fonts/c.otf")
+	assert.NoError(t, err)
+	assert.NoFileExists(t, a, "least recently used removed")
+	assert.FileExists(t, b)
+
+	fonts["fonts/b.ttf"] = "b2"
+	v.fonts.items["fonts/b.ttf"].Value.(*fontEntry).expire = time.Now().Add(-time.Second)
+	b2, err := v.loadFont(load, "fonts/b.ttf")
+	assert.NoError(t, err)
+	assert.NotEqual(t, b, b2, "changed font reloaded after expiry")
+	assert.NoFileExists(t, b)
+	buf, err := os.ReadFile(b2)
+	assert.NoError(t, err)
+	assert.Equal(t, "b2", string(buf))
+
+	for _, image := range []string{"admin/a.ttf", "fonts/../admin/a.ttf"} {
+		_, err = v.loadFont(load, image)
+		assert.Equal(t, http.StatusForbidden, imagor.WrapError(err).Code, image)
+	}
+	_, err = v.loadFont(load, "fonts/a.png")
+	assert.Equal(t, http.StatusBadRequest, imagor.WrapError(err).Code)
+
+	v.fonts.purge()
+	assert.NoFileExists(t, b2)
+}
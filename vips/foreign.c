@@ -162,7 +162,7 @@ int set_avifsave_options(VipsOperation *operation, SaveParams *params) {
       "lossless", params->heifLossless,
       "effort", 9 - params->avifSpeed, // speed is deprecated
       "strip", params->stripMetadata,
-      "subsample_mode", VIPS_FOREIGN_SUBSAMPLE_AUTO, // set to auto as we always set lossless
+      "subsample_mode", params->heifSubsample,
       NULL);
 
   if (!ret && params->quality) {
@@ -185,6 +185,23 @@ int set_jp2ksave_options(VipsOperation *operation, SaveParams *params) {
   return ret;
 }
 
+// https://www.libvips.org/API/current/VipsForeignSave.html#vips-jxlsave-buffer
+int set_jxlsave_options(VipsOperation *operation, SaveParams *params) {
+  int ret = vips_object_set(VIPS_OBJECT(operation),
+                            "strip", params->stripMetadata,
+                            "lossless", params->jxlLossless,
+                            "effort", params->jxlEffort, NULL);
+
+  // distance takes precedence over quality if both set
+  if (!ret && params->jxlDistance > 0) {
+    ret = vips_object_set(VIPS_OBJECT(operation), "distance", params->jxlDistance, NULL);
+  } else if (!ret && params->quality) {
+    ret = vips_object_set(VIPS_OBJECT(operation), "Q", params->quality, NULL);
+  }
+
+  return ret;
+}
+
 int save_to_buffer(SaveParams *params) {
   switch (params->outputFormat) {
     case JPEG:
@@ -207,6 +224,8 @@ int save_to_buffer(SaveParams *params) {
       return save_buffer("heifsave_buffer", params, set_avifsave_options);
     case JP2K:
       return save_buffer("jp2ksave_buffer", params, set_jp2ksave_options);
+    case JXL:
+      return save_buffer("jxlsave_buffer", params, set_jxlsave_options);
     default:
       g_warning("Unsupported output type given: %d", params->outputFormat);
   }
@@ -246,6 +265,7 @@ static SaveParams defaultSaveParams = {
     .webpIccProfile = NULL,
 
     .heifLossless = FALSE,
+    .heifSubsample = VIPS_FOREIGN_SUBSAMPLE_AUTO,
 
     .tiffCompression = VIPS_FOREIGN_TIFF_COMPRESSION_LZW,
     .tiffPredictor = VIPS_FOREIGN_TIFF_PREDICTOR_HORIZONTAL,
@@ -260,7 +280,11 @@ static SaveParams defaultSaveParams = {
 
     .jp2kLossless = FALSE,
     .jp2kTileHeight = 512,
-    .jp2kTileWidth = 512};
+    .jp2kTileWidth = 512,
+
+    .jxlEffort = 7,
+    .jxlDistance = 0,
+    .jxlLossless = FALSE};
 
 SaveParams create_save_params(ImageType outputFormat) {
   SaveParams params = defaultSaveParams;
@@ -128,6 +128,7 @@ type AvifExportParams struct {
 	Quality       int
 	Lossless      bool
 	Speed         int
+	SubsampleMode SubsampleMode
 }
 
 // NewAvifExportParams creates default values for an export of an AVIF image.
@@ -158,6 +159,23 @@ func NewJp2kExportParams() *Jp2kExportParams {
 	}
 }
 
+// JxlExportParams are options when exporting a JPEG XL to file or buffer.
+type JxlExportParams struct {
+	StripMetadata bool
+	Quality       int
+	Effort        int
+	Lossless      bool
+	Distance      float64
+}
+
+// NewJxlExportParams creates default values for an export of a JPEG XL image.
+func NewJxlExportParams() *JxlExportParams {
+	return &JxlExportParams{
+		Quality: 75,
+		Effort:  7,
+	}
+}
+
 func vipsSaveJPEGToBuffer(in *C.VipsImage, params JpegExportParams) ([]byte, error) {
 	p := C.create_save_params(C.JPEG)
 	p.inputImage = in
@@ -234,6 +252,7 @@ func vipsSaveAVIFToBuffer(in *C.VipsImage, params AvifExportParams) ([]byte, err
 	p.stripMetadata = C.int(boolToInt(params.StripMetadata))
 	p.heifLossless = C.int(boolToInt(params.Lossless))
 	p.avifSpeed = C.int(params.Speed)
+	p.heifSubsample = C.VipsForeignSubsample(params.SubsampleMode)
 
 	return vipsSaveToBuffer(p)
 }
@@ -251,6 +270,19 @@ func vipsSaveJP2KToBuffer(in *C.VipsImage, params Jp2kExportParams) ([]byte, err
 	return vipsSaveToBuffer(p)
 }
 
+func vipsSaveJXLToBuffer(in *C.VipsImage, params JxlExportParams) ([]byte, error) {
+	p := C.create_save_params(C.JXL)
+	p.inputImage = in
+	p.outputFormat = C.JXL
+	p.quality = C.int(params.Quality)
+	p.stripMetadata = C.int(boolToInt(params.StripMetadata))
+	p.jxlEffort = C.int(params.Effort)
+	p.jxlDistance = C.double(params.Distance)
+	p.jxlLossless = C.int(boolToInt(params.Lossless))
+
+	return vipsSaveToBuffer(p)
+}
+
 func vipsSaveGIFToBuffer(in *C.VipsImage, params GifExportParams) ([]byte, error) {
 	p := C.create_save_params(C.GIF)
 	p.inputImage = in
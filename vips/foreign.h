@@ -25,7 +25,8 @@ typedef enum types {
   HEIF,
   BMP,
   AVIF,
-  JP2K
+  JP2K,
+  JXL
 } ImageType;
 
 typedef struct SaveParams {
@@ -66,6 +67,7 @@ typedef struct SaveParams {
 
   // HEIF
   BOOL heifLossless;
+  VipsForeignSubsample heifSubsample;
 
   // TIFF
   VipsForeignTiffCompression tiffCompression;
@@ -84,6 +86,11 @@ typedef struct SaveParams {
   BOOL jp2kLossless;
   int jp2kTileWidth;
   int	jp2kTileHeight;
+
+  // JXL
+  int jxlEffort;
+  double jxlDistance;
+  BOOL jxlLossless;
 } SaveParams;
 
 SaveParams create_save_params(ImageType outputFormat);
@@ -0,0 +1,124 @@
+package vips
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+
+	"golang.org/x/image/bmp"
+)
+
+var errInvalidICO = errors.New("invalid ico")
+
+type icoEntry struct {
+	width, height int
+	bitCount      int
+	size, offset  int
+}
+
+// loadImageFromICO loads the largest image of ICO, either embedded PNG or BMP
+func loadImageFromICO(r io.Reader) (*Image, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < 6 || binary.LittleEndian.Uint16(buf[2:4]) != 1 {
+		return nil, errInvalidICO
+	}
+	var best *icoEntry
+	count := int(binary.LittleEndian.Uint16(buf[4:6]))
+	for i := 0; i < count; i++ {
+		if 6+i*16+16 > len(buf) {
+			return nil, errInvalidICO
+		}
+		d := buf[6+i*16:]
+		e := &icoEntry{
+			width:    int(d[0]),
+			height:   int(d[1]),
+			bitCount: int(binary.LittleEndian.Uint16(d[6:8])),
+			size:     int(binary.LittleEndian.Uint32(d[8:12])),
+			offset:   int(binary.LittleEndian.Uint32(d[12:16])),
+		}
+		// 0 means 256 pixels
+		if e.width == 0 {
+			e.width = 256
+		}
+		if e.height == 0 {
+			e.height = 256
+		}
+		if e.offset < 0 || e.size <= 0 || e.offset+e.size > len(buf) {
+			continue
+		}
+		if best == nil || e.width*e.height > best.width*best.height ||
+			(e.width*e.height == best.width*best.height && e.bitCount > best.bitCount) {
+			best = e
+		}
+	}
+	if best == nil {
+		return nil, errInvalidICO
+	}
+	data := buf[best.offset : best.offset+best.size]
+	if len(data) >= 8 && bytes.Equal(data[:8], []byte("\x89PNG\r\n\x1a\n")) {
+		return LoadImageFromBuffer(data, nil)
+	}
+	return loadImageFromICODIB(data)
+}
+
+// loadImageFromICODIB decodes ICO bitmap without file header,
+// of which height is doubled to include the AND mask
+func loadImageFromICODIB(data []byte) (*Image, error) {
+	if len(data) < 40 {
+		return nil, errInvalidICO
+	}
+	headerSize := int(binary.LittleEndian.Uint32(data[0:4]))
+	width := int(int32(binary.LittleEndian.Uint32(data[4:8])))
+	height := int(int32(binary.LittleEndian.Uint32(data[8:12]))) / 2
+	bitCount := int(binary.LittleEndian.Uint16(data[14:16]))
+	if headerSize < 40 || headerSize > len(data) || width <= 0 || height <= 0 {
+		return nil, errInvalidICO
+	}
+	if bitCount == 32 {
+		// BGRA bottom-up rows with alpha, which bmp decoder ignores
+		pix := data[headerSize:]
+		stride := width * 4
+		if len(pix) < stride*height {
+			return nil, errInvalidICO
+		}
+		rgba := make([]byte, stride*height)
+		for y := 0; y < height; y++ {
+			src := pix[(height-1-y)*stride:]
+			dst := rgba[y*stride:]
+			for x := 0; x < stride; x += 4 {
+				dst[x], dst[x+1], dst[x+2], dst[x+3] = src[x+2], src[x+1], src[x], src[x+3]
+			}
+		}
+		return LoadImageFromMemory(rgba, width, height, 4)
+	}
+	// prepend bmp file header with actual height for bmp decoder
+	var paletteSize int
+	if bitCount <= 8 {
+		colors := int(binary.LittleEndian.Uint32(data[32:36]))
+		if colors == 0 {
+			colors = 1 << bitCount
+		}
+		paletteSize = colors * 4
+	}
+	dib := make([]byte, len(data))
+	copy(dib, data)
+	binary.LittleEndian.PutUint32(dib[8:12], uint32(height))
+	header := make([]byte, 14)
+	copy(header, "BM")
+	binary.LittleEndian.PutUint32(header[2:6], uint32(14+len(dib)))
+	binary.LittleEndian.PutUint32(header[10:14], uint32(14+headerSize+paletteSize))
+	img, err := bmp.Decode(io.MultiReader(bytes.NewReader(header), bytes.NewReader(dib)))
+	if err != nil {
+		return nil, err
+	}
+	rect := img.Bounds()
+	rgba := image.NewRGBA(rect)
+	draw.Draw(rgba, rect, img, rect.Min, draw.Src)
+	return LoadImageFromMemory(rgba.Pix, rect.Dx(), rect.Dy(), 4)
+}
@@ -22,6 +22,9 @@ type Image struct {
 	lock   sync.Mutex
 
 	pageHeight int // cached page height
+
+	kernel   Kernel // resampling kernel of thumbnail if resample
+	resample bool
 }
 
 // Param libvips options param
@@ -154,6 +157,25 @@ func LoadThumbnailFromSource(s *Source, width, height int, crop Interesting, siz
 	return ref, nil
 }
 
+// LoadThumbnailFromBuffer loads an image buffer and creates a new Image with thumbnail crop and size
+func LoadThumbnailFromBuffer(buf []byte, width, height int, crop Interesting, size Size, params *ImportParams) (*Image, error) {
+	startupIfNeeded()
+
+	if params == nil {
+		params = NewImportParams()
+	}
+
+	vipsImage, format, err := vipsThumbnailFromBuffer(
+		buf, width, height, crop, size, params)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := newImageRef(vipsImage, format, buf)
+	log("vips", LogLevelDebug, fmt.Sprintf("created imageRef %p", ref))
+	return ref, nil
+}
+
 // LoadImageFromBuffer loads an image buffer and creates a new Image
 func LoadImageFromBuffer(buf []byte, params *ImportParams) (*Image, error) {
 	startupIfNeeded()
@@ -188,6 +210,24 @@ func LoadImageFromMemory(buf []byte, width, height, bands int) (*Image, error) {
 	return ref, nil
 }
 
+// NewTextImage renders Pango markup text to RGBA image with font description e.g. "sans bold 24",
+// optional font file, wrap width, alignment, line spacing and transparent padding in pixels
+func NewTextImage(
+	markup, font, fontFile string, width int, align Align, justify bool, spacing, pad int,
+) (*Image, error) {
+	startupIfNeeded()
+
+	vipsImage, err := vipsText(markup, font, fontFile, width, align, justify, spacing, pad)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := newImageRef(vipsImage, ImageTypeUnknown, nil)
+
+	log("vips", LogLevelDebug, fmt.Sprintf("created imageRef %p", ref))
+	return ref, nil
+}
+
 // Copy creates a new copy of the given image.
 func (r *Image) Copy() (*Image, error) {
 	out, err := vipsCopyImage(r.image)
@@ -405,6 +445,20 @@ func (r *Image) ExportAvif(params *AvifExportParams) ([]byte, error) {
 	return buf, nil
 }
 
+// ExportJxl exports the image as JPEG XL to a buffer.
+func (r *Image) ExportJxl(params *JxlExportParams) ([]byte, error) {
+	if params == nil {
+		params = NewJxlExportParams()
+	}
+
+	buf, err := vipsSaveJXLToBuffer(r.image, *params)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
 // ExportJp2k exports the image as JPEG2000 to a buffer.
 func (r *Image) ExportJp2k(params *Jp2kExportParams) ([]byte, error) {
 	if params == nil {
@@ -620,7 +674,13 @@ func (r *Image) Thumbnail(width, height int, crop Interesting) error {
 // crop decides algorithm vips uses to shrink and crop to fill target,
 // size controls upsize, downsize, both or force
 func (r *Image) ThumbnailWithSize(width, height int, crop Interesting, size Size) error {
-	out, err := vipsThumbnail(r.image, width, height, crop, size)
+	var out *C.VipsImage
+	var err error
+	if r.resample {
+		out, err = vipsResize(r.image, width, height, crop, size, r.kernel)
+	} else {
+		out, err = vipsThumbnail(r.image, width, height, crop, size)
+	}
 	if err != nil {
 		return err
 	}
@@ -628,6 +688,13 @@ func (r *Image) ThumbnailWithSize(width, height int, crop Interesting, size Size
 	return nil
 }
 
+// SetResampleKernel sets resampling kernel of subsequent thumbnail resizes,
+// instead of the libvips thumbnail default
+func (r *Image) SetResampleKernel(kernel Kernel) {
+	r.kernel = kernel
+	r.resample = true
+}
+
 // Embed embeds the given picture in a new one, i.e. the opposite of ExtractArea
 func (r *Image) Embed(left, top, width, height int, extend ExtendStrategy) error {
 	if r.Height() > r.PageHeight() {
@@ -684,6 +751,205 @@ func (r *Image) Flip(direction Direction) error {
 	return nil
 }
 
+// RotateAngle rotates each frame of the image by arbitrary angle in degrees anticlockwise,
+// enlarging the image to fit with the background color
+func (r *Image) RotateAngle(angle float64, backgroundColor *ColorRGBA) error {
+	out, err := vipsRotateAngleMultiPage(r.image, -angle, backgroundColor)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Affine applies affine transform matrix [a, b, c, d] to each frame of the image,
+// enlarging the image to fit with the background color
+func (r *Image) Affine(a, b, c, d float64, backgroundColor *ColorRGBA) error {
+	out, err := vipsAffineMultiPage(r.image, a, b, c, d, backgroundColor)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Perspective applies 3x3 homography matrix in row-major order,
+// mapping output coordinates of width and height to input coordinates of each frame,
+// with the background color outside the input
+func (r *Image) Perspective(matrix [9]float64, width, height int, backgroundColor *ColorRGBA) error {
+	out, err := vipsPerspectiveMultiPage(r.image, matrix, width, height, backgroundColor)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Stroke adds outline of radius in pixels with color around the opaque area of the image with alpha
+func (r *Image) Stroke(radius int, color *Color) error {
+	out, err := vipsStroke(r.image, radius, color)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// DropShadow adds shadow of the image with alpha beneath the image, offset by x and y,
+// blurred by sigma with color and opacity between 0 and 1.
+// Shadow is clipped within the image dimensions
+func (r *Image) DropShadow(x, y int, sigma float64, color *Color, opacity float64) error {
+	out, err := vipsDropShadow(r.image, x, y, sigma, color, opacity)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Pixelate averages each frame of the image by blocks of size in pixels
+func (r *Image) Pixelate(size int) error {
+	out, err := vipsPixelate(r.image, size)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Redact redacts region of each frame of the image by mode,
+// pixelate block size or blur sigma of param, or fill color
+func (r *Image) Redact(left, top, width, height int, mode RedactMode, param float64, color *Color) error {
+	if color == nil {
+		color = &Color{}
+	}
+	out, err := vipsRedact(r.image, left, top, width, height, mode, param, color)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Mask applies alpha of the mask image if available, or luminance otherwise,
+// as alpha channel of each frame, with mask centered within the frame and inverted if specified
+func (r *Image) Mask(mask *Image, invert bool) error {
+	out, err := vipsMask(r.image, mask.image, invert)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Gamma applies gamma correction of exponent to the color bands,
+// brightens if exponent greater than 1
+func (r *Image) Gamma(exponent float64) error {
+	out, err := vipsGamma(r.image, exponent)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// MapLUT maps the color bands through lookup table of LUTSize entries
+func (r *Image) MapLUT(lut []float64) error {
+	if len(lut) != r.LUTSize() {
+		return errors.New("vips: invalid lookup table size")
+	}
+	out, err := vipsMapLUT(r.image, lut)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// LUTSize returns size of lookup table for MapLUT, 65536 for 16-bit image or 256 otherwise
+func (r *Image) LUTSize() int {
+	if interpretation := r.Interpretation(); interpretation == InterpretationRGB16 ||
+		interpretation == InterpretationGrey16 {
+		return 65536
+	}
+	return 256
+}
+
+// Equalize equalizes histogram of the luminance, mapped to the color bands
+func (r *Image) Equalize() error {
+	out, err := vipsEqualize(r.image)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Normalize stretches the luminance range between clip and 100 - clip percentiles
+// to the full range of the color bands
+func (r *Image) Normalize(clip float64) error {
+	out, err := vipsNormalize(r.image, clip)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Recomb recombines the color bands of sRGB image by 3x3 matrix in row-major order
+func (r *Image) Recomb(matrix [9]float64) error {
+	out, err := vipsRecomb(r.image, matrix)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Duotone maps the luminance between shadow and highlight colors
+func (r *Image) Duotone(shadow, highlight *Color) error {
+	out, err := vipsDuotone(r.image, shadow, highlight)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Transparent turns pixels within tolerance distance of color into alpha of sRGB image,
+// with alpha ramp over feather distance beyond tolerance. Distances in 0 to 441 RGB euclidean scale.
+// Only regions connected to the corners of each frame if flood
+func (r *Image) Transparent(color *Color, tolerance, feather float64, flood bool) error {
+	out, err := vipsTransparent(r.image, color, tolerance, feather, flood)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// Convolution convolves color bands of the image with matrix of columns,
+// divided by sum of the matrix if normalize
+func (r *Image) Convolution(matrix []float64, columns int, normalize bool) error {
+	out, err := vipsConvolution(r.image, matrix, columns, normalize)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
+// BlurBackground scales each frame to the dimensions regardless of aspect ratio,
+// then applies gaussian blur with sigma and adds brightness offset to the color bands
+func (r *Image) BlurBackground(width, height int, sigma, brightness float64) error {
+	out, err := vipsBlurBackgroundMultiPage(r.image, width, height, sigma, brightness)
+	if err != nil {
+		return err
+	}
+	r.setImage(out)
+	return nil
+}
+
 // Rotate rotates the image by multiples of 90 degrees
 func (r *Image) Rotate(angle Angle) error {
 	if r.Height() > r.PageHeight() {
@@ -0,0 +1,104 @@
+package vips
+
+import (
+	"math"
+	"sort"
+)
+
+// levelsLUT returns lookup table of size n mapping black and white input levels
+// in 0 to 255 scale to the full range, with gamma of midtones
+func levelsLUT(n int, black, white, gamma float64) []float64 {
+	maxValue := float64(n - 1)
+	black, white = black/255*maxValue, white/255*maxValue
+	lut := make([]float64, n)
+	for i := range lut {
+		v := (float64(i) - black) / (white - black)
+		v = math.Min(math.Max(v, 0), 1)
+		if gamma > 0 && gamma != 1 {
+			v = math.Pow(v, 1/gamma)
+		}
+		lut[i] = math.Round(v * maxValue)
+	}
+	return lut
+}
+
+// curvesLUT returns lookup table of size n by monotone cubic interpolation
+// of control points in 0 to 255 scale, constant beyond the first and last points
+func curvesLUT(n int, points [][2]float64) []float64 {
+	maxValue := float64(n - 1)
+	lut := make([]float64, n)
+	if len(points) == 0 {
+		for i := range lut {
+			lut[i] = float64(i)
+		}
+		return lut
+	}
+	points = append([][2]float64(nil), points...)
+	sort.Slice(points, func(i, j int) bool { return points[i][0] < points[j][0] })
+	xs := make([]float64, 0, len(points))
+	ys := make([]float64, 0, len(points))
+	for _, p := range points {
+		if len(xs) > 0 && p[0] == xs[len(xs)-1] {
+			// last point wins for duplicated x
+			ys[len(ys)-1] = p[1]
+			continue
+		}
+		xs = append(xs, p[0])
+		ys = append(ys, p[1])
+	}
+	tangents := monotoneTangents(xs, ys)
+	for i := range lut {
+		x := float64(i) / maxValue * 255
+		lut[i] = math.Round(math.Min(math.Max(interpolate(xs, ys, tangents, x), 0), 255) / 255 * maxValue)
+	}
+	return lut
+}
+
+// monotoneTangents Fritsch-Carlson tangents of points for monotone cubic Hermite spline
+func monotoneTangents(xs, ys []float64) []float64 {
+	n := len(xs)
+	tangents := make([]float64, n)
+	if n < 2 {
+		return tangents
+	}
+	slopes := make([]float64, n-1)
+	for i := range slopes {
+		slopes[i] = (ys[i+1] - ys[i]) / (xs[i+1] - xs[i])
+	}
+	tangents[0], tangents[n-1] = slopes[0], slopes[n-2]
+	for i := 1; i < n-1; i++ {
+		if slopes[i-1]*slopes[i] <= 0 {
+			tangents[i] = 0
+		} else {
+			tangents[i] = (slopes[i-1] + slopes[i]) / 2
+		}
+	}
+	for i, s := range slopes {
+		if s == 0 {
+			tangents[i], tangents[i+1] = 0, 0
+			continue
+		}
+		a, b := tangents[i]/s, tangents[i+1]/s
+		if h := a*a + b*b; h > 9 {
+			t := 3 / math.Sqrt(h)
+			tangents[i], tangents[i+1] = t*a*s, t*b*s
+		}
+	}
+	return tangents
+}
+
+func interpolate(xs, ys, tangents []float64, x float64) float64 {
+	n := len(xs)
+	if x <= xs[0] {
+		return ys[0]
+	}
+	if x >= xs[n-1] {
+		return ys[n-1]
+	}
+	i := sort.SearchFloat64s(xs, x) - 1
+	h := xs[i+1] - xs[i]
+	t := (x - xs[i]) / h
+	t2, t3 := t*t, t*t*t
+	return (2*t3-3*t2+1)*ys[i] + (t3-2*t2+t)*h*tangents[i] +
+		(-2*t3+3*t2)*ys[i+1] + (t3-t2)*h*tangents[i+1]
+}
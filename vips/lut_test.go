@@ -0,0 +1,44 @@
+package vips
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelsLUT(t *testing.T) {
+	lut := levelsLUT(256, 0, 255, 1)
+	assert.Equal(t, float64(0), lut[0])
+	assert.Equal(t, float64(128), lut[128])
+	assert.Equal(t, float64(255), lut[255])
+
+	lut = levelsLUT(256, 20, 235, 1)
+	assert.Equal(t, float64(0), lut[10])
+	assert.Equal(t, float64(0), lut[20])
+	assert.Equal(t, float64(255), lut[235])
+	assert.Equal(t, float64(255), lut[250])
+
+	lut = levelsLUT(65536, 0, 255, 2)
+	assert.Equal(t, float64(65535), lut[65535])
+	assert.Greater(t, lut[32768], float64(32768))
+}
+
+func TestCurvesLUT(t *testing.T) {
+	lut := curvesLUT(256, [][2]float64{{0, 0}, {255, 255}})
+	for i, v := range lut {
+		assert.Equal(t, float64(i), v)
+	}
+
+	// s-curve stays monotone and passes through control points
+	lut = curvesLUT(256, [][2]float64{{255, 255}, {0, 0}, {64, 40}, {192, 215}})
+	assert.Equal(t, float64(40), lut[64])
+	assert.Equal(t, float64(215), lut[192])
+	for i := 1; i < len(lut); i++ {
+		assert.GreaterOrEqual(t, lut[i], lut[i-1])
+	}
+
+	// constant beyond end points
+	lut = curvesLUT(65536, [][2]float64{{32, 10}, {224, 250}})
+	assert.Equal(t, float64(10*257), lut[0])
+	assert.Equal(t, float64(250*257), lut[65535])
+}
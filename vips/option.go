@@ -1,6 +1,7 @@
 package vips
 
 import (
+	"github.com/kumparan/imagor/imagorpath"
 	"go.uber.org/zap"
 	"strings"
 )
@@ -12,6 +13,18 @@ type Option func(v *Processor)
 func WithFilter(name string, filter FilterFunc) Option {
 	return func(v *Processor) {
 		v.Filters[name] = filter
+		v.FilterSpecList = append(v.FilterSpecList, imagorpath.FilterSpec{
+			Name: name,
+			Args: []imagorpath.ArgSpec{{Name: "args", Type: imagorpath.ArgString, Variadic: true}},
+		})
+	}
+}
+
+// WithFilterSpec with filter option of FilterFunc and its argument schema
+func WithFilterSpec(spec imagorpath.FilterSpec, filter FilterFunc) Option {
+	return func(v *Processor) {
+		v.Filters[spec.Name] = filter
+		v.FilterSpecList = append(v.FilterSpecList, spec)
 	}
 }
 
@@ -60,6 +73,56 @@ func WithAvifSpeed(avifSpeed int) Option {
 	}
 }
 
+// WithJxlEffort with JPEG XL encoder effort option, from 1 fastest to 9 slowest
+func WithJxlEffort(effort int) Option {
+	return func(v *Processor) {
+		if effort >= 1 && effort <= 9 {
+			v.JxlEffort = effort
+		}
+	}
+}
+
+// WithMaxEffort with maximum encoder effort allowed by effort filter option, -1 for no limit
+func WithMaxEffort(effort int) Option {
+	return func(v *Processor) {
+		if effort != 0 {
+			v.MaxEffort = effort
+		}
+	}
+}
+
+// WithMaxLosslessResolution with maximum resolution of lossless AVIF, HEIF and JPEG XL output option, -1 for no limit
+func WithMaxLosslessResolution(res int) Option {
+	return func(v *Processor) {
+		if res != 0 {
+			v.MaxLosslessRes = res
+		}
+	}
+}
+
+// WithFontDir with directory option for font files loaded by text filter
+func WithFontDir(dir string) Option {
+	return func(v *Processor) {
+		v.FontDir = dir
+	}
+}
+
+// WithFontPrefix with allowed path prefix option for font files loaded by text filter
+func WithFontPrefix(prefix string) Option {
+	return func(v *Processor) {
+		v.FontPrefix = prefix
+	}
+}
+
+// WithMaxFontFiles with maximum number of font files cached option
+func WithMaxFontFiles(num int) Option {
+	return func(v *Processor) {
+		if num > 0 {
+			v.MaxFontFiles = num
+		}
+	}
+}
+
 // WithMaxFilterOps with maximum number of filter operations option
 func WithMaxFilterOps(num int) Option {
 	return func(v *Processor) {
@@ -3,6 +3,7 @@ package vips
 import (
 	"context"
 	"github.com/kumparan/imagor"
+	"github.com/kumparan/imagor/imagorpath"
 	"github.com/stretchr/testify/assert"
 	"runtime"
 	"testing"
@@ -21,6 +22,10 @@ func TestWithOption(t *testing.T) {
 			WithMaxResolution(1666667),
 			WithMozJPEG(true),
 			WithAvifSpeed(9),
+			WithJxlEffort(4),
+			WithMaxEffort(5),
+			WithFontPrefix("fonts/"),
+			WithMaxFontFiles(20),
 			WithStripMetadata(true),
 			WithDebug(true),
 			WithMaxAnimationFrames(3),
@@ -28,6 +33,12 @@ func TestWithOption(t *testing.T) {
 			WithFilter("noop", func(ctx context.Context, img *Image, load imagor.LoadFunc, args ...string) (err error) {
 				return nil
 			}),
+			WithFilterSpec(imagorpath.FilterSpec{
+				Name: "noop2",
+				Args: []imagorpath.ArgSpec{{Name: "n", Type: imagorpath.ArgInt}},
+			}, func(ctx context.Context, img *Image, load imagor.LoadFunc, args ...string) (err error) {
+				return nil
+			}),
 		)
 		assert.Equal(t, 2, v.Concurrency)
 		assert.Equal(t, 167, v.MaxFilterOps)
@@ -41,14 +52,29 @@ func TestWithOption(t *testing.T) {
 		assert.Equal(t, true, v.MozJPEG)
 		assert.Equal(t, true, v.StripMetadata)
 		assert.Equal(t, 9, v.AvifSpeed)
+		assert.Equal(t, 4, v.JxlEffort)
+		assert.Equal(t, 5, v.MaxEffort)
+		assert.Equal(t, "fonts/", v.FontPrefix)
+		assert.Equal(t, 20, v.MaxFontFiles)
 		assert.Equal(t, []string{"rgb", "fill", "watermark"}, v.DisableFilters)
+		assert.Equal(t, []string{"noop", "noop2"}, []string{v.FilterSpecs()[0].Name, v.FilterSpecs()[1].Name})
+		assert.NotNil(t, v.Filters["noop2"])
 
 	})
 	t.Run("edge options", func(t *testing.T) {
 		v := NewProcessor(
 			WithConcurrency(-1),
+			WithMaxEffort(-1),
+			WithMaxLosslessResolution(-1),
 		)
 		assert.Equal(t, runtime.NumCPU(), v.Concurrency)
+		assert.Equal(t, -1, v.MaxEffort)
+		assert.Equal(t, -1, v.MaxLosslessRes)
+	})
+	t.Run("default options", func(t *testing.T) {
+		v := NewProcessor()
+		assert.Equal(t, 6, v.MaxEffort)
+		assert.Equal(t, 4000000, v.MaxLosslessRes)
 	})
 }
 
@@ -3,6 +3,7 @@ package vips
 import (
 	"context"
 	"math"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +13,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// errMaxLosslessResolution lossless output exceeding MaxLosslessRes
+var errMaxLosslessResolution = imagor.NewError("maximum lossless resolution exceeded", http.StatusUnprocessableEntity)
+
 var imageTypeMap = map[string]ImageType{
 	"gif":    ImageTypeGIF,
 	"jpeg":   ImageTypeJPEG,
@@ -26,6 +30,16 @@ var imageTypeMap = map[string]ImageType{
 	"bmp":    ImageTypeBMP,
 	"avif":   ImageTypeAVIF,
 	"jp2":    ImageTypeJP2K,
+	"jxl":    ImageTypeJXL,
+}
+
+var kernelMap = map[string]Kernel{
+	"nearest":  KernelNearest,
+	"linear":   KernelLinear,
+	"cubic":    KernelCubic,
+	"mitchell": KernelMitchell,
+	"lanczos2": KernelLanczos2,
+	"lanczos3": KernelLanczos3,
 }
 
 // Process implements imagor.Processor interface
@@ -46,9 +60,13 @@ func (v *Processor) Process(
 		format                = ImageTypeUnknown
 		maxN                  = v.MaxAnimationFrames
 		maxBytes              int
+		targetQuality         float64
 		page                  = 1
 		dpi                   = 0
 		focalRects            []focal
+		redactions            []redaction
+		kernel                Kernel
+		resample              bool
 		err                   error
 	)
 	if p.Trim {
@@ -118,9 +136,17 @@ func (v *Processor) Process(
 				thumbnailNotSupported = true
 			}
 			break
-		case "trim", "focal", "rotate":
+		case "trim", "focal", "rotate", "affine", "perspective", "redact":
 			thumbnailNotSupported = true
 			break
+		case "resample":
+			if k, ok := kernelMap[p.Args]; ok {
+				kernel = k
+				resample = true
+				// shrink-on-load does not support kernel
+				thumbnailNotSupported = true
+			}
+			break
 		case "strip_exif":
 			stripExif = true
 		case "strip_metadata":
@@ -226,6 +252,10 @@ func (v *Processor) Process(
 	// this should be called BEFORE vipscontext.contextDone
 	defer img.Close()
 
+	if resample {
+		img.SetResampleKernel(kernel)
+	}
+
 	if orient > 0 {
 		// orient rotate before resize
 		if err = img.Rotate(getAngle(orient)); err != nil {
@@ -233,12 +263,9 @@ func (v *Processor) Process(
 		}
 	}
 	var (
-		quality     int
-		bitdepth    int
-		compression int
-		palette     bool
-		origWidth   = float64(img.Width())
-		origHeight  = float64(img.PageHeight())
+		opts       = exportOptions{StripMetadata: stripMetadata, Effort: -1}
+		origWidth  = float64(img.Width())
+		origHeight = float64(img.PageHeight())
 	)
 	if format == ImageTypeUnknown {
 		if blob.BlobType() == imagor.BlobTypeAVIF {
@@ -260,7 +287,12 @@ func (v *Processor) Process(
 		}
 		switch p.Name {
 		case "quality":
-			quality, _ = strconv.Atoi(p.Args)
+			opts.Quality, _ = strconv.Atoi(p.Args)
+			break
+		case "target_quality":
+			if f, err := strconv.ParseFloat(p.Args, 64); err == nil && f > 0 && f <= 1 {
+				targetQuality = f
+			}
 			break
 		case "autojpg":
 			format = ImageTypeJPEG
@@ -296,15 +328,64 @@ func (v *Processor) Process(
 				focalRects = append(focalRects, f)
 			}
 			break
+		case "redact":
+			r, err := parseRedaction(p.Args, origWidth, origHeight)
+			if err != nil {
+				return nil, err
+			}
+			redactions = append(redactions, r)
+			break
 		case "palette":
-			palette = true
+			opts.Palette = true
 			break
 		case "bitdepth":
-			bitdepth, _ = strconv.Atoi(p.Args)
+			opts.Bitdepth, _ = strconv.Atoi(p.Args)
 			break
 		case "compression":
-			compression, _ = strconv.Atoi(p.Args)
+			opts.Compression, _ = strconv.Atoi(p.Args)
+			break
+		case "lossless":
+			opts.Lossless = parseEnabled(p.Args)
 			break
+		case "near_lossless":
+			opts.NearLossless = parseEnabled(p.Args)
+			break
+		case "effort":
+			if n, err := strconv.Atoi(p.Args); err == nil && n >= 0 {
+				if v.MaxEffort > 0 && n > v.MaxEffort {
+					n = v.MaxEffort
+				}
+				opts.Effort = n
+			}
+			break
+		case "subsampling":
+			switch strings.ToLower(p.Args) {
+			case "444":
+				opts.Subsampling = VipsForeignSubsampleOff
+			case "420":
+				opts.Subsampling = VipsForeignSubsampleOn
+			case "auto":
+				opts.Subsampling = VipsForeignSubsampleAuto
+			}
+			break
+		case "progressive":
+			progressive := parseEnabled(p.Args)
+			opts.Progressive = &progressive
+			break
+		case "trellis":
+			opts.Trellis = parseEnabled(p.Args)
+			break
+		case "dither":
+			if f, err := strconv.ParseFloat(p.Args, 64); err == nil && f >= 0 && f <= 1 {
+				opts.Dither = &f
+			}
+			break
+		}
+	}
+	for _, r := range redactions {
+		// redact in original image coordinates before crop and resize
+		if err := r.apply(img); err != nil {
+			return nil, WrapErr(err)
 		}
 	}
 	if err := v.process(ctx, img, p, load, thumbnail, stretch, upscale, focalRects); err != nil {
@@ -315,44 +396,32 @@ func (v *Processor) Process(
 		return imagor.NewBlobFromJsonMarshal(metadata(img, format, stripExif)), nil
 	}
 	format = supportedSaveFormat(format) // convert to supported export format
-	for {
-		buf, err := v.export(img, format, compression, quality, palette, bitdepth, stripMetadata)
-		if err != nil {
+	if opts.Lossless && v.MaxLosslessRes > 0 && img.Width()*img.Height() > v.MaxLosslessRes &&
+		(format == ImageTypeAVIF || format == ImageTypeHEIF || format == ImageTypeJXL) {
+		// lossless encoding of these formats is orders of magnitude slower than lossy
+		return nil, errMaxLosslessResolution
+	}
+	var buf []byte
+	if targetQuality > 0 && isLossyFormat(format) && !opts.Lossless {
+		if opts.Quality, buf, err = v.searchTargetQuality(ctx, img, format, opts, targetQuality); err != nil {
 			return nil, WrapErr(err)
 		}
-		if maxBytes > 0 && (quality > 10 || quality == 0) && format != ImageTypePNG {
-			ln := len(buf)
-			if v.Debug {
-				v.Logger.Debug("max_bytes",
-					zap.Int("bytes", ln),
-					zap.Int("quality", quality),
-				)
-			}
-			if ln > maxBytes {
-				if quality == 0 {
-					quality = 80
-				}
-				delta := float64(ln) / float64(maxBytes)
-				switch {
-				case delta > 3:
-					quality = quality * 25 / 100
-				case delta > 1.5:
-					quality = quality * 50 / 100
-				default:
-					quality = quality * 75 / 100
-				}
-				if err := ctx.Err(); err != nil {
-					return nil, WrapErr(err)
-				}
-				continue
-			}
+	}
+	if buf == nil {
+		if buf, err = v.export(img, format, opts); err != nil {
+			return nil, WrapErr(err)
 		}
-		blob := imagor.NewBlobFromBytes(buf)
-		if typ, ok := ImageMimeTypes[format]; ok {
-			blob.SetContentType(typ)
+	}
+	if maxBytes > 0 && len(buf) > maxBytes && !opts.Lossless {
+		if buf, err = v.searchMaxBytes(ctx, img, format, opts, maxBytes, buf); err != nil {
+			return nil, WrapErr(err)
 		}
-		return blob, nil
 	}
+	out := imagor.NewBlobFromBytes(buf)
+	if typ, ok := ImageMimeTypes[format]; ok {
+		out.SetContentType(typ)
+	}
+	return out, nil
 }
 
 func (v *Processor) process(
@@ -566,7 +635,8 @@ func metadata(img *Image, format ImageType, stripExif bool) *Metadata {
 
 func supportedSaveFormat(format ImageType) ImageType {
 	switch format {
-	case ImageTypePNG, ImageTypeWEBP, ImageTypeTIFF, ImageTypeGIF, ImageTypeAVIF, ImageTypeHEIF, ImageTypeJP2K:
+	case ImageTypePNG, ImageTypeWEBP, ImageTypeTIFF, ImageTypeGIF, ImageTypeAVIF, ImageTypeHEIF, ImageTypeJP2K,
+		ImageTypeJXL:
 		if IsSaveSupported(format) {
 			return format
 		}
@@ -577,77 +647,141 @@ func supportedSaveFormat(format ImageType) ImageType {
 	return ImageTypeJPEG
 }
 
-func (v *Processor) export(
-	image *Image, format ImageType, compression int, quality int, palette bool, bitdepth int, stripMetadata bool,
-) ([]byte, error) {
+// isLossyFormat returns true if export quality trades off size against fidelity
+func isLossyFormat(format ImageType) bool {
+	switch format {
+	case ImageTypeJPEG, ImageTypeWEBP, ImageTypeAVIF, ImageTypeHEIF, ImageTypeJXL, ImageTypeJP2K:
+		return true
+	}
+	return false
+}
+
+// exportOptions per-request encoder options from output filters
+type exportOptions struct {
+	Quality       int
+	Compression   int
+	Bitdepth      int
+	Palette       bool
+	StripMetadata bool
+	Lossless      bool
+	NearLossless  bool
+	Effort        int // -1 if not specified
+	Subsampling   SubsampleMode
+	Progressive   *bool
+	Trellis       bool
+	Dither        *float64
+}
+
+func (v *Processor) export(image *Image, format ImageType, o exportOptions) ([]byte, error) {
 	switch format {
 	case ImageTypePNG:
 		opts := NewPngExportParams()
-		if quality > 0 {
-			opts.Quality = quality
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
 		}
-		if palette {
-			opts.Palette = palette
+		if o.Palette {
+			opts.Palette = o.Palette
 		}
-		if bitdepth > 0 {
-			opts.Bitdepth = bitdepth
+		if o.Bitdepth > 0 {
+			opts.Bitdepth = o.Bitdepth
 		}
-		if compression > 0 {
-			opts.Compression = compression
+		if o.Compression > 0 {
+			opts.Compression = o.Compression
 		}
-		if stripMetadata {
+		if o.StripMetadata {
 			opts.StripMetadata = true
 		}
+		if o.Progressive != nil {
+			opts.Interlace = *o.Progressive
+		}
+		if o.Dither != nil {
+			opts.Dither = *o.Dither
+		}
 		return image.ExportPng(opts)
 	case ImageTypeWEBP:
 		opts := NewWebpExportParams()
-		if quality > 0 {
-			opts.Quality = quality
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
 		}
-		if stripMetadata {
+		if o.StripMetadata {
 			opts.StripMetadata = true
 		}
+		opts.Lossless = o.Lossless
+		opts.NearLossless = o.NearLossless
+		if o.Effort >= 0 {
+			opts.ReductionEffort = min(o.Effort, 6)
+		}
 		return image.ExportWebp(opts)
 	case ImageTypeTIFF:
 		opts := NewTiffExportParams()
-		if quality > 0 {
-			opts.Quality = quality
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
 		}
-		if stripMetadata {
+		if o.StripMetadata {
 			opts.StripMetadata = true
 		}
 		return image.ExportTiff(opts)
 	case ImageTypeGIF:
 		opts := NewGifExportParams()
-		if quality > 0 {
-			opts.Quality = quality
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
 		}
-		if stripMetadata {
+		if o.StripMetadata {
 			opts.StripMetadata = true
 		}
+		if o.Effort >= 0 {
+			opts.Effort = min(max(o.Effort, 1), 10)
+		}
+		if o.Dither != nil {
+			opts.Dither = *o.Dither
+		}
 		return image.ExportGIF(opts)
 	case ImageTypeAVIF:
 		opts := NewAvifExportParams()
-		if quality > 0 {
-			opts.Quality = quality
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
 		}
-		if stripMetadata {
+		if o.StripMetadata {
 			opts.StripMetadata = true
 		}
 		opts.Speed = v.AvifSpeed
+		if o.Effort >= 0 {
+			// speed is the inverse of effort
+			opts.Speed = 9 - min(o.Effort, 9)
+		}
+		opts.Lossless = o.Lossless
+		opts.SubsampleMode = o.Subsampling
 		return image.ExportAvif(opts)
 	case ImageTypeHEIF:
 		opts := NewHeifExportParams()
-		if quality > 0 {
-			opts.Quality = quality
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
 		}
+		opts.Lossless = o.Lossless
 		return image.ExportHeif(opts)
 	case ImageTypeJP2K:
 		opts := NewJp2kExportParams()
-		if quality > 0 {
-			opts.Quality = quality
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
 		}
+		opts.Lossless = o.Lossless
 		return image.ExportJp2k(opts)
+	case ImageTypeJXL:
+		opts := NewJxlExportParams()
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
+		}
+		if o.StripMetadata {
+			opts.StripMetadata = true
+		}
+		if v.JxlEffort > 0 {
+			opts.Effort = v.JxlEffort
+		}
+		if o.Effort >= 0 {
+			opts.Effort = min(max(o.Effort, 1), 9)
+		}
+		opts.Lossless = o.Lossless
+		return image.ExportJxl(opts)
 	default:
 		opts := NewJpegExportParams()
 		if v.MozJPEG {
@@ -659,16 +793,33 @@ func (v *Processor) export(
 			opts.TrellisQuant = true
 			opts.QuantTable = 3
 		}
-		if quality > 0 {
-			opts.Quality = quality
+		if o.Quality > 0 {
+			opts.Quality = o.Quality
 		}
-		if stripMetadata {
+		if o.StripMetadata {
 			opts.StripMetadata = true
 		}
+		if o.Progressive != nil {
+			opts.Interlace = *o.Progressive
+			opts.OptimizeScans = opts.OptimizeScans && *o.Progressive
+		}
+		if o.Trellis {
+			opts.TrellisQuant = true
+		}
+		opts.SubsampleMode = o.Subsampling
 		return image.ExportJpeg(opts)
 	}
 }
 
+// parseEnabled parses optional boolean filter argument, enabled if omitted
+func parseEnabled(args string) bool {
+	if args == "" {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(args)
+	return enabled
+}
+
 func argSplit(r rune) bool {
 	return r == 'x' || r == ',' || r == ':'
 }
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/kumparan/imagor"
+	"github.com/kumparan/imagor/imagorpath"
 	"go.uber.org/zap"
 )
 
@@ -38,9 +39,17 @@ type Processor struct {
 	MozJPEG            bool
 	StripMetadata      bool
 	AvifSpeed          int
+	JxlEffort          int
+	MaxEffort          int
+	MaxLosslessRes     int
+	FontDir            string
+	FontPrefix         string
+	MaxFontFiles       int
 	Debug              bool
+	FilterSpecList     []imagorpath.FilterSpec
 
 	disableFilters map[string]bool
+	fonts          *fontCache
 }
 
 // NewProcessor create Processor
@@ -52,23 +61,45 @@ func NewProcessor(options ...Option) *Processor {
 		Concurrency:        1,
 		MaxFilterOps:       -1,
 		MaxAnimationFrames: -1,
+		MaxEffort:          6,
+		MaxLosslessRes:     4000000,
+		MaxFontFiles:       100,
 		Logger:             zap.NewNop(),
 		disableFilters:     map[string]bool{},
 	}
 	v.Filters = FilterMap{
 		"watermark":        v.watermark,
+		"layer":            v.layer,
+		"border":           border,
+		"shadow":           shadow,
+		"vignette":         vignette,
+		"mask":             v.mask,
 		"round_corner":     roundCorner,
 		"rotate":           rotate,
+		"affine":           affine,
+		"perspective":      perspective,
 		"label":            label,
+		"text":             v.text,
 		"grayscale":        grayscale,
 		"brightness":       brightness,
 		"background_color": backgroundColor,
 		"contrast":         contrast,
 		"modulate":         modulate,
+		"gamma":            gamma,
+		"levels":           levels,
+		"curves":           curves,
+		"normalize":        normalize,
+		"equalize":         equalize,
+		"sepia":            sepia,
+		"duotone":          duotone,
+		"colorize":         colorize,
+		"gradient":         gradient,
+		"transparent":      transparent,
 		"hue":              hue,
 		"saturation":       saturation,
 		"rgb":              rgb,
 		"blur":             blur,
+		"convolution":      convolution,
 		"sharpen":          sharpen,
 		"strip_icc":        stripIcc,
 		"strip_exif":       stripExif,
@@ -76,6 +107,7 @@ func NewProcessor(options ...Option) *Processor {
 		"set_frames":       setFrames,
 		"padding":          v.padding,
 		"proportion":       proportion,
+		"pixelate":         pixelate,
 	}
 	for _, option := range options {
 		option(v)
@@ -89,6 +121,7 @@ func NewProcessor(options ...Option) *Processor {
 	if v.Concurrency == -1 {
 		v.Concurrency = runtime.NumCPU()
 	}
+	v.fonts = newFontCache(v.MaxFontFiles)
 	return v
 }
 
@@ -133,12 +166,54 @@ func (v *Processor) Shutdown(_ context.Context) error {
 		return nil
 	}
 	processorCount--
+	v.fonts.purge()
 	if processorCount == 0 {
 		Shutdown()
 	}
 	return nil
 }
 
+// SupportsFormat implements imagor.FormatSupporter interface
+func (v *Processor) SupportsFormat(format string) bool {
+	imageType, ok := imageTypeMap[format]
+	return ok && supportedSaveFormat(imageType) == imageType
+}
+
+// DefaultQuality implements imagor.QualityDefaulter interface
+func (v *Processor) DefaultQuality(format string) int {
+	imageType, ok := imageTypeMap[format]
+	if !ok {
+		return 0
+	}
+	switch supportedSaveFormat(imageType) {
+	case ImageTypeJPEG:
+		if v.MozJPEG {
+			return 75
+		}
+		return NewJpegExportParams().Quality
+	case ImageTypeWEBP:
+		return NewWebpExportParams().Quality
+	case ImageTypeTIFF:
+		return NewTiffExportParams().Quality
+	case ImageTypeGIF:
+		return NewGifExportParams().Quality
+	case ImageTypeAVIF:
+		return NewAvifExportParams().Quality
+	case ImageTypeHEIF:
+		return NewHeifExportParams().Quality
+	case ImageTypeJP2K:
+		return NewJp2kExportParams().Quality
+	case ImageTypeJXL:
+		return NewJxlExportParams().Quality
+	}
+	return 0
+}
+
+// FilterSpecs implements imagor.FilterSpecProvider interface
+func (v *Processor) FilterSpecs() []imagorpath.FilterSpec {
+	return v.FilterSpecList
+}
+
 func newImageFromBlob(
 	ctx context.Context, blob *imagor.Blob, params *ImportParams,
 ) (*Image, error) {
@@ -149,6 +224,13 @@ func newImageFromBlob(
 		buf, width, height, bands, _ := blob.Memory()
 		return LoadImageFromMemory(buf, width, height, bands)
 	}
+	if buf, ok := blob.Mmap(ctx); ok && isCacheDisabled() {
+		// zero-copy load from memory-mapped file, kept mapped until request done
+		if img, err := LoadImageFromBuffer(buf, params); err == nil ||
+			(blob.BlobType() != imagor.BlobTypeBMP && blob.BlobType() != imagor.BlobTypeICO) {
+			return img, err
+		}
+	}
 	reader, _, err := blob.NewReader()
 	if err != nil {
 		return nil, err
@@ -156,8 +238,8 @@ func newImageFromBlob(
 	src := NewSource(reader)
 	contextDefer(ctx, src.Close)
 	img, err := src.LoadImage(params)
-	if err != nil && blob.BlobType() == imagor.BlobTypeBMP {
-		// fallback with Go BMP decoder if vips error on BMP
+	if err != nil && (blob.BlobType() == imagor.BlobTypeBMP || blob.BlobType() == imagor.BlobTypeICO) {
+		// fallback with Go decoder if vips error on BMP, or ICO without magick loader
 		src.Close()
 		r, _, err := blob.NewReader()
 		if err != nil {
@@ -166,6 +248,9 @@ func newImageFromBlob(
 		defer func() {
 			_ = r.Close()
 		}()
+		if blob.BlobType() == imagor.BlobTypeICO {
+			return loadImageFromICO(r)
+		}
 		return loadImageFromBMP(r)
 	}
 	return img, err
@@ -178,6 +263,9 @@ func newThumbnailFromBlob(
 	if blob == nil || blob.IsEmpty() {
 		return nil, imagor.ErrNotFound
 	}
+	if buf, ok := blob.Mmap(ctx); ok && isCacheDisabled() {
+		return LoadThumbnailFromBuffer(buf, width, height, crop, size, params)
+	}
 	reader, _, err := blob.NewReader()
 	if err != nil {
 		return nil, err
@@ -363,7 +451,15 @@ func (v *Processor) CheckResolution(img *Image, err error) (*Image, error) {
 }
 
 func isMultiPage(blob *imagor.Blob, n, page int) bool {
-	return blob != nil && (blob.SupportsAnimation() || blob.BlobType() == imagor.BlobTypePDF) && ((n != 1 && n != 0) || (page != 1 && page != 0))
+	if blob == nil || ((n == 1 || n == 0) && (page == 1 || page == 0)) {
+		return false
+	}
+	switch blob.BlobType() {
+	case imagor.BlobTypeGIF, imagor.BlobTypeWEBP, imagor.BlobTypePDF:
+		return true
+	}
+	// APNG frames not supported by libvips png loader, loaded as static image
+	return false
 }
 
 func applyMultiPageParams(params *ImportParams, n, page int) {
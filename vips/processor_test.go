@@ -35,6 +35,38 @@ type test struct {
 	arm64Golden   bool
 }
 
+// assertTest asserts properties of the result image instead of comparing with golden file.
+// Zero values are not asserted.
+type assertTest struct {
+	name        string
+	path        string
+	contentType string
+	width       int
+	height      int // page height
+	minWidth    int
+	minHeight   int
+	frames      int
+	bands       int
+	maxBytes    int
+	pixels      []pixel
+}
+
+// pixel expects leading band values and alpha at x, y of all frames stacked vertically,
+// e.g. y of page height plus 8 is 8 pixels down the second frame
+type pixel struct {
+	x, y   int
+	values []float64
+	alpha  float64 // negative if not asserted
+}
+
+func colourAt(x, y int, values ...float64) pixel {
+	return pixel{x: x, y: y, values: values, alpha: -1}
+}
+
+func alphaAt(x, y int, alpha float64) pixel {
+	return pixel{x: x, y: y, alpha: alpha}
+}
+
 func TestProcessor(t *testing.T) {
 	v := NewProcessor(WithDebug(true))
 	require.NoError(t, v.Startup(context.Background()))
@@ -213,6 +245,84 @@ func TestProcessor(t *testing.T) {
 			{name: "svg", path: "test.svg", checkTypeOnly: true},
 		}, WithDebug(true), WithLogger(zap.NewExample()))
 	})
+	t.Run("vips operations assertions", func(t *testing.T) {
+		doAssertTests(t, []assertTest{
+			{name: "png encoder params", path: "200x200/filters:format(png):palette():dither(0.5):progressive()/gopher.png", contentType: "image/png", width: 200, height: 200},
+			{name: "jpeg encoder params", path: "200x200/filters:format(jpeg):progressive(false):subsampling(444):trellis()/gopher.png", contentType: "image/jpeg", width: 200, height: 200, bands: 3},
+			{name: "webp encoder params", path: "200x200/filters:format(webp):lossless():effort(2)/gopher.png", contentType: "image/webp", width: 200, height: 200, bands: 4},
+			{name: "max_bytes png", path: "filters:max_bytes(20000):format(png)/gopher.png", contentType: "image/png", maxBytes: 20000},
+			{name: "target_quality", path: "filters:target_quality(0.95):format(jpg):fill(white)/gopher.png", contentType: "image/jpeg", width: 1634, height: 2224, bands: 3},
+			{name: "target_quality webp", path: "filters:target_quality(0.9):quality(80):format(webp)/gopher.png", contentType: "image/webp", width: 1634, height: 2224, bands: 4},
+			{name: "rotate angle", path: "fit-in/200x200/filters:rotate(3.5):format(png)/gopher.png", contentType: "image/png", minWidth: 148, minHeight: 201, bands: 4},
+			{name: "rotate angle background", path: "fit-in/200x200/filters:rotate(-15,yellow):format(jpg)/demo1.jpg", contentType: "image/jpeg", minWidth: 201, minHeight: 201, bands: 3},
+			{name: "rotate angle crop", path: "fit-in/200x200/filters:rotate(3.5,crop):format(jpg)/demo1.jpg", contentType: "image/jpeg", width: 188, height: 188},
+			{name: "layers", path: "fit-in/200x200/filters:fill(white):layer(gopher-front.png,10p,center,0,multiply,40p,none,-15):layer(gopher-front.png,right,bottom,30,screen,50):format(jpg)/demo1.jpg", contentType: "image/jpeg", width: 200, height: 200},
+			{name: "pixelate", path: "fit-in/200x200/filters:pixelate(10):format(png)/gopher.png", contentType: "image/png", width: 147, height: 200, bands: 4},
+			{name: "redact", path: "200x100/filters:redact(100,100,400,300):redact(0.5x0.1:0.9x0.4,blur):redact(0,0,0.1,0.1,ff0000):format(jpg)/demo1.jpg", contentType: "image/jpeg", width: 200, height: 100},
+			{name: "affine", path: "fit-in/200x200/filters:affine(1,0.3,0,1,none):format(png)/demo1.jpg", contentType: "image/png", minWidth: 201, minHeight: 200, bands: 4},
+			{name: "perspective", path: "fit-in/200x200/filters:perspective(0.1,0,0.9,0.1,1,1,0,0.9):format(jpg)/demo1.jpg", contentType: "image/jpeg", width: 201, height: 181},
+			{name: "fit-in fill blur sigma brightness", path: "fit-in/320x180/filters:fill(blur,20,-30):format(jpg)/gopher-front.png", contentType: "image/jpeg", width: 320, height: 180},
+			{name: "border shadow vignette", path: "fit-in/200x150/filters:vignette(40):border(5,ffffff):shadow(5,8,6,000000,50):format(png)/demo1.jpg", contentType: "image/png", width: 196, height: 196, bands: 4,
+				pixels: []pixel{alphaAt(0, 0, 0), colourAt(14, 11, 255, 255, 255), alphaAt(14, 11, 255)}},
+			{name: "border transparent shadow", path: "fit-in/200x150/filters:border(10,none):shadow(-4,4,0,red,100)/gopher-front.png", contentType: "image/png", width: 141, height: 174, bands: 4,
+				pixels: []pixel{alphaAt(0, 0, 0)}},
+			{name: "mask circle", path: "200x200/filters:mask(circle):format(png)/demo1.jpg", contentType: "image/png", width: 200, height: 200, bands: 4,
+				pixels: []pixel{alphaAt(0, 0, 0), alphaAt(100, 100, 255)}},
+			{name: "mask image invert", path: "fit-in/200x150/filters:mask(gopher-front.png,invert):format(png)/demo1.jpg", contentType: "image/png", width: 150, height: 150, bands: 4},
+			{name: "mask path", path: "fit-in/200x150/filters:mask(path%3AM50%200%20L100%20100%20L0%20100%20Z):format(png)/gopher.png", contentType: "image/png", width: 110, height: 150, bands: 4,
+				pixels: []pixel{alphaAt(0, 0, 0)}},
+			{name: "gamma levels", path: "filters:gamma(1.5):levels(20,230,0.9)/levels.png", contentType: "image/png", width: 64, height: 16,
+				pixels: []pixel{colourAt(8, 8, 88, 88, 88), colourAt(24, 8, 164, 164, 164), colourAt(40, 8, 229, 229, 229), colourAt(56, 8, 238, 133, 70)}},
+			{name: "gamma levels 16-bit", path: "filters:gamma(1.5):levels(20,230,0.9)/levels-16bit.png", contentType: "image/png", width: 64, height: 16,
+				pixels: []pixel{colourAt(8, 8, 22883, 22883, 22883), colourAt(24, 8, 42116, 42116, 42116), colourAt(40, 8, 58997, 58997, 58997), colourAt(56, 8, 60999, 34091, 18131)}},
+			{name: "curves", path: "filters:curves(0:0,64:40,192:215,255:255)/levels.png", contentType: "image/png", width: 64, height: 16,
+				pixels: []pixel{colourAt(8, 8, 40, 40, 40), colourAt(24, 8, 127, 127, 127), colourAt(40, 8, 215, 215, 215), colourAt(56, 8, 222, 85, 28)}},
+			{name: "normalize equalize", path: "filters:normalize(1):equalize()/levels.png", contentType: "image/png", width: 64, height: 16,
+				pixels: []pixel{colourAt(8, 8, 64, 64, 64), colourAt(24, 8, 128, 128, 128), colourAt(40, 8, 255, 255, 255), colourAt(56, 8, 255, 64, 64)}},
+			{name: "levels alpha", path: "filters:levels(30,220):curves(0:20,255:235):normalize():format(png)/levels-alpha.png", contentType: "image/png", width: 64, height: 16, bands: 4,
+				pixels: []pixel{colourAt(8, 8, 0, 0, 0), colourAt(24, 8, 128, 128, 128), colourAt(40, 8, 255, 255, 255), colourAt(56, 8, 255, 71, 0), alphaAt(56, 8, 128)}},
+			{name: "levels grayscale alpha", path: "fit-in/filters:gamma(0.8):levels(10,240):curves(0:0,128:150,255:255):equalize():normalize(2)/2bands.png", contentType: "image/png", width: 293, height: 115},
+			{name: "sepia duotone", path: "filters:sepia():duotone(1a1a40,ffd166)/levels.png", contentType: "image/png", width: 64, height: 16,
+				pixels: []pixel{colourAt(8, 8, 96, 82, 76), colourAt(24, 8, 166, 138, 87), colourAt(40, 8, 235, 193, 99), colourAt(56, 8, 160, 133, 86)}},
+			{name: "resample nearest", path: "fit-in/300x300/filters:upscale():resample(nearest)/gopher.png", contentType: "image/png", width: 220, height: 300},
+			{name: "resample linear crop", path: "100x100/filters:resample(linear)/demo1.jpg", contentType: "image/jpeg", width: 100, height: 100},
+			{name: "resample nearest edges", path: "30x10/filters:resample(nearest):format(png)/memory-test.png", contentType: "image/png", width: 30, height: 10,
+				pixels: []pixel{colourAt(9, 5, 255, 0, 0), colourAt(10, 5, 0, 255, 0), colourAt(19, 5, 0, 255, 0), colourAt(20, 5, 0, 0, 255)}},
+			{name: "convolution", path: "fit-in/200x150/filters:convolution(1;2;1;2;4;2;1;2;1,3,true)/demo1.jpg", contentType: "image/jpeg", width: 150, height: 150,
+				pixels: []pixel{colourAt(0, 0, 255, 255, 255)}},
+			{name: "convolution edge", path: "fit-in/200x150/filters:convolution(-1;-1;-1;-1;8;-1;-1;-1;-1,3,false)/gopher.png", contentType: "image/png", width: 110, height: 150, bands: 4},
+			{name: "transparent", path: "fit-in/200x150/filters:transparent(ffffff,10,5):format(png)/demo1.jpg", contentType: "image/png", width: 150, height: 150, bands: 4,
+				pixels: []pixel{alphaAt(0, 0, 0), alphaAt(75, 75, 255)}},
+			{name: "transparent flood", path: "fit-in/200x150/filters:transparent(auto,15,10,flood):format(png)/demo1.jpg", contentType: "image/png", width: 150, height: 150, bands: 4,
+				pixels: []pixel{alphaAt(0, 0, 0), alphaAt(75, 75, 255)}},
+			{name: "colorize gradient", path: "filters:colorize(ff0066,30):gradient(90,none,000000,80)/levels.png", contentType: "image/png", width: 64, height: 16,
+				pixels: []pixel{colourAt(8, 8, 70, 26, 43), colourAt(24, 8, 96, 52, 69), colourAt(40, 8, 121, 77, 95), colourAt(56, 8, 124, 40, 38), colourAt(56, 15, 49, 16, 15)}},
+			{name: "tints alpha", path: "fit-in/200x150/filters:sepia(60):gradient(45,red,blue,50):format(png)/gopher-front.png", contentType: "image/png", width: 117, height: 150, bands: 4},
+			{name: "tints grayscale", path: "fit-in/filters:sepia():colorize(red):duotone(black,white)/2bands.png", contentType: "image/png", width: 293, height: 115,
+				pixels: []pixel{colourAt(122, 4, 74, 74, 74), alphaAt(0, 0, 0)}},
+			{name: "apng static first frame", path: "fit-in/16x16/animated.apng", contentType: "image/png", width: 16, height: 16, frames: 1,
+				pixels: []pixel{colourAt(8, 8, 255, 0, 0), alphaAt(8, 8, 255)}},
+			{name: "layer animated", path: "fit-in/100x100/filters:layer(gopher-front.png,center,center,20,overlay,50p,50p,10)/dancing-banana.gif", contentType: "image/gif", width: 95, height: 100, frames: 8},
+			{name: "pixelate redact animated", path: "fit-in/100x100/filters:redact(0.2,0.2,0.6,0.6,blur):pixelate(4)/dancing-banana.gif", contentType: "image/gif", width: 95, height: 100, frames: 8},
+			{name: "rotate angle animated", path: "fit-in/100x150/filters:rotate(10,crop)/dancing-banana.gif", contentType: "image/gif", width: 85, height: 92, frames: 8},
+			{name: "fit-in fill blur animated", path: "fit-in/200x100/filters:fill(blur,10)/dancing-banana.gif", contentType: "image/gif", width: 200, height: 100, frames: 8},
+			{name: "animated border shadow vignette", path: "fit-in/150x150/filters:vignette(50):border(3,white):shadow(3,3,4,black,60):format(gif)/dancing-banana.gif", contentType: "image/gif", width: 151, height: 158, frames: 8,
+				pixels: []pixel{colourAt(9, 9, 255, 255, 255)}},
+			{name: "animated mask hexagon", path: "fit-in/150x150/filters:mask(hexagon):format(gif)/dancing-banana.gif", contentType: "image/gif", width: 121, height: 128, frames: 8,
+				pixels: []pixel{alphaAt(0, 0, 0)}},
+			{name: "animated levels", path: "filters:levels(20,230,1.2):normalize():format(gif)/levels.gif", contentType: "image/gif", width: 32, height: 16, frames: 2,
+				pixels: []pixel{colourAt(8, 8, 0, 0, 0), colourAt(24, 8, 135, 135, 135), colourAt(8, 24, 255, 255, 255), colourAt(24, 24, 255, 78, 0)}},
+			{name: "animated tints", path: "filters:duotone(navy,yellow):gradient(0,ff0000,none,60):format(gif)/levels.gif", contentType: "image/gif", width: 32, height: 16, frames: 2,
+				pixels: []pixel{colourAt(0, 8, 177, 26, 39), colourAt(31, 8, 129, 127, 63), colourAt(0, 24, 229, 79, 13), colourAt(31, 24, 129, 127, 63)}},
+			{name: "animated resample nearest", path: "fit-in/100x100/filters:resample(nearest)/dancing-banana.gif", contentType: "image/gif", width: 95, height: 100, frames: 8},
+			{name: "text", path: "fit-in/300x200/filters:fill(yellow):text(Hello%20World%0AIMAGOR,center,bottom,32,white,0,sans,width:80p,align:center,line_height:1.2,stroke:2:000000,shadow:2:2:3:000000,background:0000ff:8:12:30)/gopher-front.png", contentType: "image/png", width: 300, height: 200,
+				pixels: []pixel{colourAt(0, 0, 255, 255, 0)}},
+			{name: "text markup", path: "fit-in/300x200/filters:fill(white):text(%3Cb%3EIMAGOR%3C%2Fb%3E%20text,10,10p,24,red,20,monospace,markup,width:150,align:justify)/gopher-front.png", contentType: "image/png", width: 300, height: 200,
+				pixels: []pixel{colourAt(299, 199, 255, 255, 255)}},
+			{name: "text animated", path: "fit-in/150x200/10x00:10x50/filters:fill(cyan):text(IMAGOR,center,-30,25,white,0,sans,stroke:1,shadow:1:1:1)/dancing-banana.gif", contentType: "image/gif", width: 150, height: 200, frames: 8,
+				pixels: []pixel{colourAt(0, 0, 0, 255, 255)}},
+		}, WithDebug(true), WithLogger(zap.NewExample()))
+	})
 	t.Run("max frames", func(t *testing.T) {
 		var resultDir = filepath.Join(testDataDir, "golden/max-frames")
 		doGoldenTests(t, resultDir, []test{
@@ -290,6 +400,60 @@ func TestProcessor(t *testing.T) {
 		assert.Equal(t, 406, w.Code)
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
 	})
+	t.Run("invalid redact", func(t *testing.T) {
+		app := newTestApp(t, WithDebug(true))
+		for _, path := range []string{
+			"filters:redact(10,20,abc,80)/demo1.jpg",
+			"filters:redact(10,20,30)/demo1.jpg",
+			"filters:redact(110,20,10,80,blur)/demo1.jpg",
+			"filters:redact(10x20:110,blur)/demo1.jpg",
+		} {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(
+				http.MethodGet, "/unsafe/"+path, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(
+			http.MethodGet, "/unsafe/filters:redact(300,300,400,400)/demo1.jpg", nil))
+		assert.Equal(t, http.StatusOK, w.Code, "region outside of image")
+	})
+	t.Run("invalid convolution", func(t *testing.T) {
+		app := newTestApp(t, WithDebug(true))
+		for _, path := range []string{
+			"filters:convolution(1;2;1;2,3)/demo1.jpg",
+			"filters:convolution(1;2;a,3)/demo1.jpg",
+			"filters:convolution(" + strings.Repeat("1;", 16*16-1) + "1,16)/demo1.jpg",
+			"filters:convolution(" + strings.Repeat("1;", 16-1) + "1,1)/demo1.jpg",
+		} {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(
+				http.MethodGet, "/unsafe/"+path, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(
+			http.MethodGet, "/unsafe/filters:convolution("+strings.Repeat("1;", 15*15-1)+"1,15,true)/demo1.jpg", nil))
+		assert.Equal(t, http.StatusOK, w.Code, "15x15 matrix")
+	})
+	t.Run("target quality large image", func(t *testing.T) {
+		buf, err := os.ReadFile(filepath.Join(testDataDir, "gopher.png"))
+		require.NoError(t, err)
+		img, err := LoadImageFromBuffer(buf, nil)
+		require.NoError(t, err)
+		defer img.Close()
+		require.NoError(t, img.Flatten(&Color{R: 255, G: 255, B: 255}))
+		ctx := context.Background()
+		low, _, err := v.searchTargetQuality(ctx, img, ImageTypeJPEG, exportOptions{}, 0.9)
+		require.NoError(t, err)
+		high, out, err := v.searchTargetQuality(ctx, img, ImageTypeJPEG, exportOptions{}, 0.98)
+		require.NoError(t, err)
+		require.NotEmpty(t, out, "target not reached")
+		// artifacts are compared at full resolution instead of hidden by downscale
+		assert.GreaterOrEqual(t, high, 30)
+		assert.Less(t, high, 100)
+		assert.GreaterOrEqual(t, high, low)
+	})
 	t.Run("resolution exceeded", func(t *testing.T) {
 		app := imagor.New(
 			imagor.WithLoaders(filestorage.New(testDataDir)),
@@ -362,6 +526,18 @@ func TestProcessor(t *testing.T) {
 			http.MethodGet, "/unsafe/dancing-banana.gif", nil))
 		assert.Equal(t, 422, w.Code)
 	})
+	t.Run("lossless resolution exceeded", func(t *testing.T) {
+		app := newTestApp(t, WithMaxLosslessResolution(100*100), WithDebug(true))
+		for format, code := range map[string]int{"avif": 422, "jxl": 422, "webp": 200} {
+			if supportedSaveFormat(imageTypeMap[format]) == ImageTypeJPEG {
+				continue // encoder not available
+			}
+			path := "/unsafe/200x200/filters:format(" + format + "):lossless()/gopher-front.png"
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, code, w.Code, path)
+		}
+	})
 	t.Run("invalid BMP", func(t *testing.T) {
 		ctx := context.Background()
 		blob := imagor.NewBlobFromBytes([]byte("BMabcdasdfasdfasdfasdfasdfasdfasdfasdfasdfasdf"))
@@ -380,37 +556,7 @@ func doGoldenTests(t *testing.T, resultDir string, tests []test, opts ...Option)
 		filestorage.WithSaveErrIfExists(true))
 	resultDirArm64 := strings.ReplaceAll(resultDir, "/golden", "/golden_arm64")
 	resStorageArm64 := filestorage.New(resultDirArm64, filestorage.WithSaveErrIfExists(true))
-	fileLoader := filestorage.New(testDataDir)
-	processor := NewProcessor(opts...)
-
-	loader := loaderFunc(func(r *http.Request, image string) (blob *imagor.Blob, err error) {
-		image, _ = fileLoader.Path(image)
-		return imagor.NewBlob(func() (reader io.ReadCloser, size int64, err error) {
-			// unknown size to force enable seek
-			reader, err = os.Open(image)
-			return
-		}), nil
-	})
-	app := imagor.New(
-		imagor.WithLoaders(loader, loaderFunc(func(r *http.Request, image string) (blob *imagor.Blob, err error) {
-			if strings.HasPrefix(image, "memory-test") {
-				return imagor.NewBlobFromMemory([]byte{
-					255, 0, 0,
-					0, 255, 0,
-					0, 0, 255,
-				}, 3, 1, 3), nil
-			}
-			return nil, imagor.ErrNotFound
-		})),
-		imagor.WithUnsafe(true),
-		imagor.WithDebug(true),
-		imagor.WithLogger(zap.NewExample()),
-		imagor.WithProcessors(processor),
-	)
-	require.NoError(t, app.Startup(context.Background()))
-	t.Cleanup(func() {
-		assert.NoError(t, app.Shutdown(context.Background()))
-	})
+	app := newTestApp(t, opts...)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
@@ -456,6 +602,92 @@ func doGoldenTests(t *testing.T, resultDir string, tests []test, opts ...Option)
 	}
 }
 
+func doAssertTests(t *testing.T, tests []assertTest, opts ...Option) {
+	app := newTestApp(t, opts...)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(
+				http.MethodGet, fmt.Sprintf("/unsafe/%s", tt.path), nil))
+			require.Equal(t, 200, w.Code, w.Body.String())
+			buf := w.Body.Bytes()
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, imagor.NewBlobFromBytes(buf).ContentType())
+			}
+			if tt.maxBytes > 0 {
+				assert.LessOrEqual(t, len(buf), tt.maxBytes)
+			}
+			params := NewImportParams()
+			params.NumPages.Set(-1)
+			img, err := LoadImageFromBuffer(buf, params)
+			require.NoError(t, err)
+			defer img.Close()
+			if tt.width > 0 {
+				assert.Equal(t, tt.width, img.Width(), "width mismatch")
+			}
+			if tt.height > 0 {
+				assert.Equal(t, tt.height, img.PageHeight(), "height mismatch")
+			}
+			assert.GreaterOrEqual(t, img.Width(), tt.minWidth, "width too small")
+			assert.GreaterOrEqual(t, img.PageHeight(), tt.minHeight, "height too small")
+			if tt.frames > 0 {
+				assert.Equal(t, tt.frames, img.Pages(), "frames mismatch")
+			}
+			if tt.bands > 0 {
+				assert.Equal(t, tt.bands, img.Bands(), "bands mismatch")
+			}
+			for _, p := range tt.pixels {
+				values, err := img.GetPoint(p.x, p.y)
+				require.NoError(t, err)
+				require.GreaterOrEqual(t, len(values), len(p.values))
+				for i, v := range p.values {
+					assert.InDelta(t, v, values[i], 8, "pixel %d,%d band %d mismatch", p.x, p.y, i)
+				}
+				if p.alpha >= 0 {
+					require.True(t, img.HasAlpha(), "alpha missing")
+					assert.InDelta(t, p.alpha, values[len(values)-1], 8, "pixel %d,%d alpha mismatch", p.x, p.y)
+				}
+			}
+		})
+	}
+}
+
+// newTestApp creates an unsafe imagor app serving testdata with the vips processor
+func newTestApp(t *testing.T, opts ...Option) *imagor.Imagor {
+	fileLoader := filestorage.New(testDataDir)
+	processor := NewProcessor(opts...)
+
+	loader := loaderFunc(func(r *http.Request, image string) (blob *imagor.Blob, err error) {
+		image, _ = fileLoader.Path(image)
+		return imagor.NewBlob(func() (reader io.ReadCloser, size int64, err error) {
+			// unknown size to force enable seek
+			reader, err = os.Open(image)
+			return
+		}), nil
+	})
+	app := imagor.New(
+		imagor.WithLoaders(loader, loaderFunc(func(r *http.Request, image string) (blob *imagor.Blob, err error) {
+			if strings.HasPrefix(image, "memory-test") {
+				return imagor.NewBlobFromMemory([]byte{
+					255, 0, 0,
+					0, 255, 0,
+					0, 0, 255,
+				}, 3, 1, 3), nil
+			}
+			return nil, imagor.ErrNotFound
+		})),
+		imagor.WithUnsafe(true),
+		imagor.WithDebug(true),
+		imagor.WithLogger(zap.NewExample()),
+		imagor.WithProcessors(processor),
+	)
+	require.NoError(t, app.Startup(context.Background()))
+	t.Cleanup(func() {
+		assert.NoError(t, app.Shutdown(context.Background()))
+	})
+	return app
+}
+
 type loaderFunc func(r *http.Request, image string) (blob *imagor.Blob, err error)
 
 func (f loaderFunc) Get(r *http.Request, image string) (*imagor.Blob, error) {
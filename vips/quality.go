@@ -0,0 +1,164 @@
+package vips
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// minSearchQuality lower bound of quality searches
+const minSearchQuality = 10
+
+// searchTargetQuality binary searches the lowest quality of which the encoded image
+// reaches SSIM target against the processed image, compared at output resolution on sampled tiles,
+// capped by the quality if specified.
+// Returns the quality found along with the encoded buffer if available
+func (v *Processor) searchTargetQuality(
+	ctx context.Context, img *Image, format ImageType, opts exportOptions, target float64,
+) (int, []byte, error) {
+	width, height := img.Width(), img.PageHeight()
+	tiles := newSSIMTiles(width, height)
+	ref, err := img.ssimSample(tiles)
+	if err != nil {
+		return 0, nil, err
+	}
+	hi := 100
+	if opts.Quality > 0 {
+		hi = opts.Quality
+	}
+	lo := min(minSearchQuality, hi)
+	best := hi
+	var bestBuf []byte
+	for lo <= hi {
+		if err := ctx.Err(); err != nil {
+			return 0, nil, err
+		}
+		opts.Quality = (lo + hi) / 2
+		buf, err := v.export(img, format, opts)
+		if err != nil {
+			return 0, nil, err
+		}
+		score, err := similarity(buf, ref, width, height, tiles)
+		if err != nil {
+			return 0, nil, err
+		}
+		if v.Debug {
+			v.Logger.Debug("target_quality",
+				zap.Int("bytes", len(buf)),
+				zap.Int("quality", opts.Quality),
+				zap.Float64("ssim", score),
+			)
+		}
+		if score >= target {
+			best, bestBuf = opts.Quality, buf
+			hi = opts.Quality - 1
+		} else {
+			lo = opts.Quality + 1
+		}
+	}
+	return best, bestBuf, nil
+}
+
+// searchMaxBytes binary searches the highest quality of which the encoded image fits within max bytes.
+// PNG is palette quantised, then reduced bit depth if still not fit.
+// Returns the smallest buffer found if none fits
+func (v *Processor) searchMaxBytes(
+	ctx context.Context, img *Image, format ImageType, opts exportOptions, maxBytes int, buf []byte,
+) ([]byte, error) {
+	var hi int
+	switch {
+	case format == ImageTypePNG:
+		hi = 100
+		if opts.Palette && opts.Quality > 0 {
+			// already quantised at quality
+			hi = opts.Quality - 1
+		} else if opts.Quality > 0 {
+			hi = opts.Quality
+		}
+		opts.Palette = true
+	case isLossyFormat(format):
+		hi = opts.Quality - 1
+		if opts.Quality == 0 {
+			hi = 80
+		}
+	default:
+		return buf, nil
+	}
+	smallest := buf
+	fit := false
+	lo := minSearchQuality
+	for lo <= hi {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		opts.Quality = (lo + hi) / 2
+		b, err := v.export(img, format, opts)
+		if err != nil {
+			return nil, err
+		}
+		if v.Debug {
+			v.Logger.Debug("max_bytes",
+				zap.Int("bytes", len(b)),
+				zap.Int("quality", opts.Quality),
+			)
+		}
+		if len(b) <= maxBytes {
+			buf, fit = b, true
+			lo = opts.Quality + 1
+		} else {
+			if len(b) < len(smallest) {
+				smallest = b
+			}
+			hi = opts.Quality - 1
+		}
+	}
+	if fit {
+		return buf, nil
+	}
+	if format == ImageTypePNG {
+		opts.Quality = minSearchQuality
+		for _, bitdepth := range []int{4, 2, 1} {
+			if opts.Bitdepth > 0 && opts.Bitdepth <= bitdepth {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			opts.Bitdepth = bitdepth
+			b, err := v.export(img, format, opts)
+			if err != nil {
+				return nil, err
+			}
+			if v.Debug {
+				v.Logger.Debug("max_bytes",
+					zap.Int("bytes", len(b)),
+					zap.Int("bitdepth", bitdepth),
+				)
+			}
+			if len(b) < len(smallest) {
+				smallest = b
+			}
+			if len(b) <= maxBytes {
+				return b, nil
+			}
+		}
+	}
+	return smallest, nil
+}
+
+// similarity decodes buf and returns its SSIM against reference pixels of the tiles
+func similarity(buf, ref []byte, width, height int, tiles ssimTiles) (float64, error) {
+	img, err := LoadImageFromBuffer(buf, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer img.Close()
+	if img.Width() != width || img.PageHeight() != height {
+		return 0, nil
+	}
+	pix, err := img.ssimSample(tiles)
+	if err != nil {
+		return 0, err
+	}
+	return ssim(ref, pix, tiles), nil
+}
@@ -0,0 +1,84 @@
+package vips
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kumparan/imagor"
+)
+
+// redaction region of original image coordinates with mode
+type redaction struct {
+	focal
+	mode  RedactMode
+	color *Color
+}
+
+// parseRedaction parses redact filter args left,top,right,bottom[,mode]
+// in pixels or ratios of the image dimensions, e.g. 10,20,110,120,blur or 0.1x0.2:0.3x0.4.
+// Mode is either pixelate by default, blur or fill color
+func parseRedaction(args string, width, height float64) (r redaction, err error) {
+	parts := strings.Split(args, ",")
+	mode := "pixelate"
+	if n := len(parts); n == 5 || n == 2 {
+		// mode following the four coordinates or the region
+		mode = strings.ToLower(parts[n-1])
+		parts = parts[:n-1]
+	}
+	coords := strings.FieldsFunc(strings.Join(parts, ","), argSplit)
+	if len(coords) != 4 {
+		return r, imagor.NewError("invalid redact region "+args, http.StatusBadRequest)
+	}
+	var v [4]float64
+	for i, s := range coords {
+		var e error
+		if v[i], e = strconv.ParseFloat(s, 64); e != nil {
+			return r, imagor.NewError("invalid redact region "+args, http.StatusBadRequest)
+		}
+	}
+	r.Left, r.Top, r.Right, r.Bottom = v[0], v[1], v[2], v[3]
+	if r.Left < 0 || r.Top < 0 || r.Right <= r.Left || r.Bottom <= r.Top {
+		return r, imagor.NewError("invalid redact region "+args, http.StatusBadRequest)
+	}
+	if r.Left < 1 && r.Top < 1 && r.Right <= 1 && r.Bottom <= 1 {
+		r.Left *= width
+		r.Right *= width
+		r.Top *= height
+		r.Bottom *= height
+	}
+	r.Left = math.Max(math.Round(r.Left), 0)
+	r.Top = math.Max(math.Round(r.Top), 0)
+	r.Right = math.Min(math.Round(r.Right), width)
+	r.Bottom = math.Min(math.Round(r.Bottom), height)
+	switch mode {
+	case "pixelate":
+		r.mode = RedactPixelate
+	case "blur":
+		r.mode = RedactBlur
+	default:
+		r.mode = RedactFill
+		r.color = getColor(nil, mode)
+	}
+	return r, nil
+}
+
+// apply redacts the region of image, with block size and sigma relative to the region size
+func (r redaction) apply(img *Image) error {
+	width := int(r.Right - r.Left)
+	height := int(r.Bottom - r.Top)
+	if width <= 0 || height <= 0 {
+		// region outside of image
+		return nil
+	}
+	size := float64(max(width, height))
+	switch r.mode {
+	case RedactPixelate:
+		return img.Redact(int(r.Left), int(r.Top), width, height, r.mode, math.Max(math.Round(size/8), 4), nil)
+	case RedactBlur:
+		return img.Redact(int(r.Left), int(r.Top), width, height, r.mode, math.Max(size/8, 2), nil)
+	default:
+		return img.Redact(int(r.Left), int(r.Top), width, height, r.mode, 0, r.color)
+	}
+}
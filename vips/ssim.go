@@ -0,0 +1,122 @@
+package vips
+
+const (
+	// ssimTileSize size of the full resolution tiles compared by SSIM
+	ssimTileSize = 128
+	// ssimGrid maximum number of tiles sampled along each dimension
+	ssimGrid = 6
+)
+
+const (
+	ssimWindow = 8
+	ssimStep   = 4
+	ssimC1     = (0.01 * 255) * (0.01 * 255)
+	ssimC2     = (0.03 * 255) * (0.03 * 255)
+)
+
+// ssimWeights weights of Y, Cb and Cr channels, chroma counts less as less perceived
+var ssimWeights = [3]float64{0.8, 0.1, 0.1}
+
+// ssimTiles full resolution tiles sampled evenly over the image,
+// covering the whole image if it fits within ssimGrid tiles along the dimension
+type ssimTiles struct {
+	left, top     []int
+	width, height int
+}
+
+func newSSIMTiles(width, height int) ssimTiles {
+	t := ssimTiles{width: min(width, ssimTileSize), height: min(height, ssimTileSize)}
+	for _, y := range tilePositions(height, t.height) {
+		for _, x := range tilePositions(width, t.width) {
+			t.left = append(t.left, x)
+			t.top = append(t.top, y)
+		}
+	}
+	return t
+}
+
+func tilePositions(size, tile int) []int {
+	if tile <= 0 {
+		return nil
+	}
+	n := min((size+tile-1)/tile, ssimGrid)
+	if n <= 1 {
+		return []int{0}
+	}
+	pos := make([]int, n)
+	for i := range pos {
+		pos[i] = (size - tile) * i / (n - 1)
+	}
+	return pos
+}
+
+// ssimSample returns 8-bit sRGB pixels of the tiles of the first page joined in a row
+func (r *Image) ssimSample(t ssimTiles) ([]byte, error) {
+	return vipsSSIMTiles(r.image, t.left, t.top, t.width, t.height)
+}
+
+// ssim structural similarity of 8-bit sRGB pixels a and b of the tiles joined in a row,
+// averaged over sliding windows within each tile and weighted over YCbCr channels.
+// Returns 0 if dimensions mismatch
+func ssim(a, b []byte, t ssimTiles) float64 {
+	n := len(t.left)
+	stride := n * t.width
+	if n == 0 || t.width <= 0 || t.height <= 0 ||
+		len(a) != stride*t.height*3 || len(b) != stride*t.height*3 {
+		return 0
+	}
+	pa, pb := ycbcr(a), ycbcr(b)
+	win := min(ssimWindow, t.width, t.height)
+	var score float64
+	for c, weight := range ssimWeights {
+		var total float64
+		var count int
+		for i := 0; i < n; i++ {
+			for y := 0; y+win <= t.height; y += ssimStep {
+				for x := i * t.width; x+win <= (i+1)*t.width; x += ssimStep {
+					total += ssimWindowAt(pa[c], pb[c], stride, x, y, win)
+					count++
+				}
+			}
+		}
+		score += weight * total / float64(count)
+	}
+	return score
+}
+
+// ycbcr converts interleaved 8-bit sRGB pixels to planar full range YCbCr
+func ycbcr(pix []byte) (p [3][]float64) {
+	n := len(pix) / 3
+	for c := range p {
+		p[c] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		r, g, b := float64(pix[i*3]), float64(pix[i*3+1]), float64(pix[i*3+2])
+		p[0][i] = 0.299*r + 0.587*g + 0.114*b
+		p[1][i] = 128 - 0.168736*r - 0.331264*g + 0.5*b
+		p[2][i] = 128 + 0.5*r - 0.418688*g - 0.081312*b
+	}
+	return
+}
+
+func ssimWindowAt(a, b []float64, stride, x, y, win int) float64 {
+	var sumA, sumB, sumAA, sumBB, sumAB float64
+	for j := y; j < y+win; j++ {
+		for i := x; i < x+win; i++ {
+			pa := a[j*stride+i]
+			pb := b[j*stride+i]
+			sumA += pa
+			sumB += pb
+			sumAA += pa * pa
+			sumBB += pb * pb
+			sumAB += pa * pb
+		}
+	}
+	n := float64(win * win)
+	meanA, meanB := sumA/n, sumB/n
+	varA := sumAA/n - meanA*meanA
+	varB := sumBB/n - meanB*meanB
+	covar := sumAB/n - meanA*meanB
+	return ((2*meanA*meanB + ssimC1) * (2*covar + ssimC2)) /
+		((meanA*meanA + meanB*meanB + ssimC1) * (varA + varB + ssimC2))
+}
@@ -180,3 +180,9 @@ func ReadVipsMemStats(stats *MemoryStats) {
 	stats.Allocs = int64(C.vips_tracked_get_allocs())
 	stats.Files = int64(C.vips_tracked_get_files())
 }
+
+// isCacheDisabled returns true if libvips operation cache is disabled,
+// so that loaded buffers are not retained beyond image lifetime
+func isCacheDisabled() bool {
+	return int(C.vips_cache_get_max()) == 0
+}
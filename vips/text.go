@@ -0,0 +1,236 @@
+package vips
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/kumparan/imagor"
+	"github.com/kumparan/imagor/imagorpath"
+)
+
+// textOptions text filter options following the positional arguments as key:value
+type textOptions struct {
+	width      int
+	align      Align
+	justify    bool
+	lineHeight float64
+	markup     bool
+	fontFile   string
+
+	stroke      int
+	strokeColor *Color
+
+	shadowX, shadowY int
+	shadowSigma      float64
+	shadowColor      *Color
+	shadowOpacity    float64
+
+	background        *Color
+	backgroundPadding int
+	backgroundRadius  int
+	backgroundOpacity float64
+}
+
+// maxStroke maximum stroke width of text in pixels
+const maxStroke = 20
+
+// text renders rich text with Pango markup, wrapping, stroke, shadow and background pill:
+// text(text[,x[,y[,size[,color[,alpha[,font[,option...]]]]]]]) with options
+// width:w align:left|center|right|justify line_height:n markup fontfile:path
+// stroke:w[:color] shadow:x:y[:sigma[:color[:opacity]]] background:color[:padding[:radius[:alpha]]]
+func (v *Processor) text(ctx context.Context, img *Image, load imagor.LoadFunc, args ...string) (err error) {
+	ln := len(args)
+	if ln == 0 {
+		return
+	}
+	var (
+		text  = unescapeArg(args[0])
+		font  = "sans"
+		size  = 20
+		c     = &Color{}
+		alpha float64
+	)
+	if text == "" {
+		return
+	}
+	if ln > 3 {
+		if n, _ := strconv.Atoi(args[3]); n > 0 {
+			size = n
+		}
+	}
+	if ln > 4 && args[4] != "" {
+		c = getColor(img, args[4])
+	}
+	if ln > 5 {
+		alpha, _ = strconv.ParseFloat(args[5], 64)
+		alpha /= 100
+	}
+	if ln > 6 && args[6] != "" {
+		font = unescapeArg(args[6])
+	}
+	o := textOptions{
+		align:             AlignLow,
+		strokeColor:       &Color{},
+		shadowColor:       &Color{},
+		shadowOpacity:     0.5,
+		backgroundOpacity: 1,
+	}
+	if ln > 7 {
+		for _, arg := range args[7:] {
+			if err = o.parse(ctx, v, img, load, arg); err != nil {
+				return
+			}
+		}
+	}
+	if !o.markup {
+		text = html.EscapeString(text)
+	}
+	markup := fmt.Sprintf(`<span foreground="#%02x%02x%02x">%s</span>`, c.R, c.G, c.B, text)
+	var spacing int
+	if o.lineHeight > 1 {
+		spacing = int(math.Round(float64(size) * (o.lineHeight - 1)))
+	}
+	// margin around the text box for stroke, shadow and background
+	pad := o.stroke + max(abs(o.shadowX), abs(o.shadowY)) + int(math.Ceil(o.shadowSigma*3))
+	if o.background != nil {
+		pad = max(pad, o.backgroundPadding)
+	}
+	var overlay *Image
+	if overlay, err = NewTextImage(
+		markup, font+" "+strconv.Itoa(size), o.fontFile, o.width, o.align, o.justify, spacing, pad,
+	); err != nil {
+		return
+	}
+	contextDefer(ctx, overlay.Close)
+	if o.stroke > 0 {
+		if err = overlay.Stroke(o.stroke, o.strokeColor); err != nil {
+			return
+		}
+	}
+	if o.shadowX != 0 || o.shadowY != 0 || o.shadowSigma > 0 {
+		if err = overlay.DropShadow(o.shadowX, o.shadowY, o.shadowSigma, o.shadowColor, o.shadowOpacity); err != nil {
+			return
+		}
+	}
+	// text box dimensions without margin
+	var boxPad int
+	if o.background != nil {
+		boxPad = o.backgroundPadding
+	}
+	boxW := overlay.Width() - (pad-boxPad)*2
+	boxH := overlay.PageHeight() - (pad-boxPad)*2
+	if o.background != nil {
+		var pill *Image
+		if pill, err = LoadImageFromBuffer([]byte(fmt.Sprintf(`
+			<svg width="%d" height="%d" viewBox="0 0 %d %d">
+				<rect rx="%d" ry="%d" x="%d" y="%d" width="%d" height="%d"
+				 fill="#%02x%02x%02x" fill-opacity="%g"/>
+			</svg>
+		`, overlay.Width(), overlay.PageHeight(), overlay.Width(), overlay.PageHeight(),
+			o.backgroundRadius, o.backgroundRadius, pad-boxPad, pad-boxPad, boxW, boxH,
+			o.background.R, o.background.G, o.background.B, o.backgroundOpacity)), nil); err != nil {
+			return
+		}
+		contextDefer(ctx, pill.Close)
+		if err = pill.Composite(overlay, BlendModeOver, 0, 0); err != nil {
+			return
+		}
+		overlay = pill
+	}
+	if alpha > 0 {
+		if err = overlay.Linear([]float64{1, 1, 1, 1 - alpha}, []float64{0, 0, 0, 0}); err != nil {
+			return
+		}
+	}
+	var x, y int
+	if ln > 1 {
+		x, _ = getPosition(args[1], img.Width(), boxW, imagorpath.HAlignLeft, imagorpath.HAlignRight)
+	}
+	if ln > 2 {
+		y, _ = getPosition(args[2], img.PageHeight(), boxH, imagorpath.VAlignTop, imagorpath.VAlignBottom)
+	}
+	return compositeOverlay(img, overlay, x-(pad-boxPad), y-(pad-boxPad), BlendModeOver)
+}
+
+func (o *textOptions) parse(ctx context.Context, v *Processor, img *Image, load imagor.LoadFunc, arg string) error {
+	parts := strings.Split(arg, ":")
+	name, values := parts[0], parts[1:]
+	value := func(i int) string {
+		if i < len(values) {
+			return values[i]
+		}
+		return ""
+	}
+	switch name {
+	case "width":
+		o.width = getSize(value(0), img.Width())
+	case "align":
+		switch value(0) {
+		case "center":
+			o.align = AlignCenter
+		case imagorpath.HAlignRight:
+			o.align = AlignHigh
+		case "justify":
+			o.justify = true
+		}
+	case "line_height":
+		o.lineHeight, _ = strconv.ParseFloat(value(0), 64)
+	case "markup":
+		o.markup = parseEnabled(value(0))
+	case "fontfile":
+		filename, err := v.loadFont(load, unescapeArg(value(0)))
+		if err != nil {
+			return err
+		}
+		o.fontFile = filename
+	case "stroke":
+		o.stroke, _ = strconv.Atoi(value(0))
+		o.stroke = min(max(o.stroke, 0), maxStroke)
+		if s := value(1); s != "" {
+			o.strokeColor = getColor(img, s)
+		}
+	case "shadow":
+		o.shadowX, _ = strconv.Atoi(value(0))
+		o.shadowY, _ = strconv.Atoi(value(1))
+		o.shadowSigma, _ = strconv.ParseFloat(value(2), 64)
+		o.shadowSigma = math.Min(math.Max(o.shadowSigma, 0), 100)
+		if s := value(3); s != "" {
+			o.shadowColor = getColor(img, s)
+		}
+		if s := value(4); s != "" {
+			// opacity same as shadow filter, unlike alpha of text and background
+			if f, e := strconv.ParseFloat(s, 64); e == nil {
+				o.shadowOpacity = math.Min(math.Max(f, 0), 100) / 100
+			}
+		}
+	case "background":
+		o.background = getColor(img, value(0))
+		o.backgroundPadding, _ = strconv.Atoi(value(1))
+		o.backgroundPadding = max(o.backgroundPadding, 0)
+		o.backgroundRadius, _ = strconv.Atoi(value(2))
+		if s := value(3); s != "" {
+			a, _ := strconv.ParseFloat(s, 64)
+			o.backgroundOpacity = 1 - a/100
+		}
+	}
+	return ctx.Err()
+}
+
+func unescapeArg(s string) string {
+	if a, e := url.QueryUnescape(s); e == nil {
+		return a
+	}
+	return s
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
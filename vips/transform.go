@@ -0,0 +1,65 @@
+package vips
+
+import "math"
+
+// inscribedRect returns the dimensions of the largest axis-aligned rectangle
+// within rectangle of w and h rotated by angle in degrees
+func inscribedRect(w, h, angle float64) (float64, float64) {
+	if w <= 0 || h <= 0 {
+		return 0, 0
+	}
+	rad := angle * math.Pi / 180
+	sin, cos := math.Abs(math.Sin(rad)), math.Abs(math.Cos(rad))
+	long, short := math.Max(w, h), math.Min(w, h)
+	if short <= 2*sin*cos*long || math.Abs(sin-cos) < 1e-10 {
+		// half constrained, two opposite corners touch the longer side
+		x := 0.5 * short
+		if w >= h {
+			return x / sin, x / cos
+		}
+		return x / cos, x / sin
+	}
+	// fully constrained, all four corners touch the sides
+	cos2 := cos*cos - sin*sin
+	return (w*cos - h*sin) / cos2, (h*cos - w*sin) / cos2
+}
+
+// homography returns 3x3 perspective matrix in row-major order mapping
+// the four src points to the four dst points, as x0, y0, ... x3, y3.
+// Returns false if points are degenerate
+func homography(src, dst [8]float64) (m [9]float64, ok bool) {
+	// solve the 8x8 linear system of h0..h7 with h8 = 1
+	var a [8][9]float64
+	for i := 0; i < 4; i++ {
+		u, v := src[i*2], src[i*2+1]
+		x, y := dst[i*2], dst[i*2+1]
+		a[i*2] = [9]float64{u, v, 1, 0, 0, 0, -u * x, -v * x, x}
+		a[i*2+1] = [9]float64{0, 0, 0, u, v, 1, -u * y, -v * y, y}
+	}
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for row := col + 1; row < 8; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return m, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for row := 0; row < 8; row++ {
+			if row == col {
+				continue
+			}
+			f := a[row][col] / a[col][col]
+			for k := col; k < 9; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+		}
+	}
+	for i := 0; i < 8; i++ {
+		m[i] = a[i][8] / a[i][i]
+	}
+	m[8] = 1
+	return m, true
+}
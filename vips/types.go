@@ -22,6 +22,7 @@ const (
 	ImageTypeBMP
 	ImageTypeAVIF
 	ImageTypeJP2K
+	ImageTypeJXL
 )
 
 // IsSaveSupported indicates if image type supports save
@@ -70,6 +71,9 @@ func vipsDetermineImageTypeFromMetaLoader(in *C.VipsImage) ImageType {
 			if strings.HasPrefix(vipsLoader, "jp2k") {
 				return ImageTypeJP2K
 			}
+			if strings.HasPrefix(vipsLoader, "jxl") {
+				return ImageTypeJXL
+			}
 			if strings.HasPrefix(vipsLoader, "magick") {
 				return ImageTypeMagick
 			}
@@ -92,6 +96,7 @@ var ImageTypes = map[ImageType]string{
 	ImageTypeBMP:    "bmp",
 	ImageTypeAVIF:   "avif",
 	ImageTypeJP2K:   "jp2k",
+	ImageTypeJXL:    "jxl",
 }
 
 // ImageMimeTypes map the various image types to its mime type representation
@@ -107,6 +112,7 @@ var ImageMimeTypes = map[ImageType]string{
 	ImageTypeBMP:  "image/bmp",
 	ImageTypeAVIF: "image/avif",
 	ImageTypeJP2K: "image/jp2",
+	ImageTypeJXL:  "image/jxl",
 }
 
 // Color represents an RGB
@@ -212,6 +218,16 @@ const (
 	Angle270 Angle = C.VIPS_ANGLE_D270
 )
 
+// RedactMode redaction mode of image region
+type RedactMode int
+
+// RedactMode enum
+const (
+	RedactPixelate RedactMode = 0
+	RedactBlur     RedactMode = 1
+	RedactFill     RedactMode = 2
+)
+
 // Angle45 represents VIPS_ANGLE45 type
 type Angle45 int
 
@@ -256,6 +272,20 @@ const (
 	InterestingLast      Interesting = C.VIPS_INTERESTING_LAST
 )
 
+// Kernel represents VipsKernel type
+// https://libvips.github.io/libvips/API/current/libvips-resample.html#VipsKernel
+type Kernel int
+
+// Kernel enum
+const (
+	KernelNearest  Kernel = C.VIPS_KERNEL_NEAREST
+	KernelLinear   Kernel = C.VIPS_KERNEL_LINEAR
+	KernelCubic    Kernel = C.VIPS_KERNEL_CUBIC
+	KernelMitchell Kernel = C.VIPS_KERNEL_MITCHELL
+	KernelLanczos2 Kernel = C.VIPS_KERNEL_LANCZOS2
+	KernelLanczos3 Kernel = C.VIPS_KERNEL_LANCZOS3
+)
+
 // SubsampleMode correlates to a libvips subsample mode
 type SubsampleMode int
 
@@ -81,6 +81,75 @@ int thumbnail_buffer(void *buf, size_t len, VipsImage **out,
                               "crop", crop, "size", size, NULL);
 }
 
+// resize_image same as thumbnail_image with crop and size, resampled by kernel without shrink
+int resize_image(VipsImage *in, VipsImage **out, int width, int height,
+                 int crop, int size, int kernel) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 6);
+  VipsImage *resized;
+  int page_height, n_pages, resized_height;
+  double hscale, vscale;
+
+  if (vips_autorot(in, &t[0], NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  page_height = vips_image_get_page_height(t[0]);
+  n_pages = t[0]->Ysize / page_height;
+  hscale = (double) width / t[0]->Xsize;
+  vscale = (double) height / page_height;
+  if (crop != VIPS_INTERESTING_NONE) {
+    hscale = vscale = VIPS_MAX(hscale, vscale);
+  } else if (size != VIPS_SIZE_FORCE) {
+    hscale = vscale = VIPS_MIN(hscale, vscale);
+  }
+  if (size == VIPS_SIZE_DOWN) {
+    hscale = VIPS_MIN(hscale, 1);
+    vscale = VIPS_MIN(vscale, 1);
+  } else if (size == VIPS_SIZE_UP) {
+    hscale = VIPS_MAX(hscale, 1);
+    vscale = VIPS_MAX(vscale, 1);
+  }
+  // whole number of rows per page
+  resized_height = VIPS_MAX(1, (int) rint(page_height * vscale));
+  vscale = (double) resized_height / page_height;
+
+  if (vips_image_hasalpha(t[0])) {
+    if (vips_premultiply(t[0], &t[1], NULL) ||
+        vips_resize(t[1], &t[2], hscale, "vscale", vscale, "kernel", kernel, NULL) ||
+        vips_unpremultiply(t[2], &t[3], NULL) ||
+        vips_cast(t[3], &t[4], t[0]->BandFmt, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+    resized = t[4];
+  } else {
+    if (vips_resize(t[0], &t[4], hscale, "vscale", vscale, "kernel", kernel, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+    resized = t[4];
+  }
+  if (crop != VIPS_INTERESTING_NONE && n_pages == 1 &&
+      (resized->Xsize > width || resized->Ysize > height)) {
+    if (vips_smartcrop(resized, &t[5], VIPS_MIN(width, resized->Xsize),
+                       VIPS_MIN(height, resized->Ysize), "interesting", crop, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+    resized = t[5];
+  }
+  if (vips_copy(resized, out, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  if (n_pages > 1) {
+    vips_image_set_int(*out, VIPS_META_PAGE_HEIGHT, resized_height);
+  }
+  clear_image(&base);
+  return 0;
+}
+
 void clear_image(VipsImage **image) {
   // https://developer.gnome.org/gobject/stable/gobject-The-Base-Object-Type.html#g-clear-object
   if (G_IS_OBJECT(*image)) g_clear_object(image);
@@ -202,6 +271,116 @@ int embed_multi_page_image_background(VipsImage *in, VipsImage **out, int left,
   return 0;
 }
 
+static VipsArrayDouble *background_array(VipsImage *in, double r, double g, double b, double a) {
+  double background[3] = {r, g, b};
+  double backgroundRGBA[4] = {r, g, b, a};
+
+  if (in->Bands <= 3) {
+    return vips_array_double_new(background, 3);
+  }
+  return vips_array_double_new(backgroundRGBA, 4);
+}
+
+// transform_pages applies transform mode to each frame with background:
+// 0 rotate by angle of params[0],
+// 1 affine by matrix of params[0..3],
+// 2 perspective by 3x3 matrix of params[0..8] mapping output to input of width and height
+static int transform_pages(VipsImage *in, VipsImage **out, int mode, double *params,
+                           int width, int height, double r, double g, double b, double a) {
+  VipsArrayDouble *vipsBackground = background_array(in, r, g, b, a);
+  VipsObject *base = VIPS_OBJECT(vips_image_new());
+  int page_height = vips_image_get_page_height(in);
+  int in_width = in->Xsize;
+  int n_pages = in->Ysize / page_height;
+
+  VipsImage **page = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **transformed = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **t = (VipsImage **) vips_object_local_array(base, 7);
+
+  if (mode == 2) {
+    // index image of input coordinates for each output pixel
+    t[0] = vips_image_new_matrix_from_array(3, 3, params, 9);
+    if (
+      t[0] == NULL ||
+      vips_xyz(&t[1], width, height, NULL) ||
+      vips_bandjoin_const1(t[1], &t[2], 1.0, NULL) ||
+      vips_recomb(t[2], &t[3], t[0], NULL) ||
+      vips_extract_band(t[3], &t[4], 0, "n", 2, NULL) ||
+      vips_extract_band(t[3], &t[5], 2, NULL) ||
+      vips_divide(t[4], t[5], &t[6], NULL)
+    ) {
+      vips_area_unref(VIPS_AREA(vipsBackground));
+      g_object_unref(base);
+      return -1;
+    }
+  }
+
+  // split image into transformed frames
+  for (int i = 0; i < n_pages; i++) {
+    int err = vips_extract_area(in, &page[i], 0, page_height * i, in_width, page_height, NULL);
+    if (!err) {
+      switch (mode) {
+        case 0:
+          err = vips_rotate(page[i], &transformed[i], params[0],
+            "background", vipsBackground, NULL);
+          break;
+        case 1:
+          err = vips_affine(page[i], &transformed[i], params[0], params[1], params[2], params[3],
+            "background", vipsBackground, NULL);
+          break;
+        default:
+          err = vips_mapim(page[i], &transformed[i], t[6],
+            "background", vipsBackground, NULL);
+      }
+    }
+    if (err) {
+      vips_area_unref(VIPS_AREA(vipsBackground));
+      g_object_unref(base);
+      return -1;
+    }
+  }
+  // reassemble frames and set page height
+  // copy before modifying metadata
+  if (n_pages == 1) {
+    if (vips_copy(transformed[0], out, NULL)) {
+      vips_area_unref(VIPS_AREA(vipsBackground));
+      g_object_unref(base);
+      return -1;
+    }
+  } else {
+    VipsImage **copy = (VipsImage **) vips_object_local_array(base, 1);
+    if (
+      vips_arrayjoin(transformed, &copy[0], n_pages, "across", 1, NULL) ||
+      vips_copy(copy[0], out, NULL)
+    ) {
+      vips_area_unref(VIPS_AREA(vipsBackground));
+      g_object_unref(base);
+      return -1;
+    }
+    vips_image_set_int(*out, VIPS_META_PAGE_HEIGHT, transformed[0]->Ysize);
+  }
+  vips_area_unref(VIPS_AREA(vipsBackground));
+  g_object_unref(base);
+  return 0;
+}
+
+int rotate_angle_multi_page(VipsImage *in, VipsImage **out, double angle,
+                            double r, double g, double b, double a) {
+  double params[1] = {angle};
+  return transform_pages(in, out, 0, params, 0, 0, r, g, b, a);
+}
+
+int affine_multi_page(VipsImage *in, VipsImage **out, double m0, double m1, double m2, double m3,
+                      double r, double g, double b, double a) {
+  double params[4] = {m0, m1, m2, m3};
+  return transform_pages(in, out, 1, params, 0, 0, r, g, b, a);
+}
+
+int perspective_multi_page(VipsImage *in, VipsImage **out, double *matrix, int width, int height,
+                           double r, double g, double b, double a) {
+  return transform_pages(in, out, 2, matrix, width, height, r, g, b, a);
+}
+
 int flip_image(VipsImage *in, VipsImage **out, int direction) {
   return vips_flip(in, out, direction, NULL);
 }
@@ -240,6 +419,62 @@ int extract_area_multi_page(VipsImage *in, VipsImage **out, int left, int top, i
   return 0;
 }
 
+int blur_background_multi_page(VipsImage *in, VipsImage **out, int width, int height,
+                               double sigma, double brightness) {
+  VipsObject *base = VIPS_OBJECT(vips_image_new());
+  int page_height = vips_image_get_page_height(in);
+  int in_width = in->Xsize;
+  int n_pages = in->Ysize / page_height;
+  int n_bands = vips_image_hasalpha(in) ? in->Bands - 1 : in->Bands;
+  double a[in->Bands], b[in->Bands];
+
+  VipsImage **page = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **scaled = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **blurred = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **lighten = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **background = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **copy = (VipsImage **) vips_object_local_array(base, 1);
+
+  for (int i = 0; i < in->Bands; i++) {
+    a[i] = 1;
+    b[i] = i < n_bands ? brightness : 0;
+  }
+
+  // scale up and blur each frame to cover the target dimensions
+  for (int i = 0; i < n_pages; i++) {
+    if (
+      vips_extract_area(in, &page[i], 0, page_height * i, in_width, page_height, NULL) ||
+      vips_thumbnail_image(page[i], &scaled[i], width, "height", height, "size", VIPS_SIZE_FORCE, NULL) ||
+      vips_gaussblur(scaled[i], &blurred[i], sigma, NULL)
+    ) {
+      g_object_unref(base);
+      return -1;
+    }
+    if (brightness == 0) {
+      background[i] = blurred[i];
+      g_object_ref(background[i]);
+    } else if (
+      vips_linear(blurred[i], &lighten[i], a, b, in->Bands, NULL) ||
+      vips_cast(lighten[i], &background[i], blurred[i]->BandFmt, NULL)
+    ) {
+      g_object_unref(base);
+      return -1;
+    }
+  }
+  // reassemble frames and set page height
+  // copy before modifying metadata
+  if(
+    vips_arrayjoin(background, &copy[0], n_pages, "across", 1, NULL) ||
+    vips_copy(copy[0], out, NULL)
+  ) {
+    g_object_unref(base);
+    return -1;
+  }
+  vips_image_set_int(*out, VIPS_META_PAGE_HEIGHT, height);
+  g_object_unref(base);
+  return 0;
+}
+
 int rotate_image(VipsImage *in, VipsImage **out, VipsAngle angle) {
   return vips_rot(in, out, angle, NULL);
 }
@@ -346,6 +581,119 @@ int label_image(VipsImage *in, VipsImage **out,
   return 0;
 }
 
+int text_image(VipsImage **out, const char *text, const char *font, const char *fontfile,
+               int width, VipsAlign align, int justify, int spacing, int pad) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 1);
+  VipsOperation *op = vips_operation_new("text");
+
+  // rgba for color emoji, text color by markup
+  if (vips_object_set(VIPS_OBJECT(op),
+        "text", text, "font", font, "dpi", 72, "rgba", TRUE,
+        "align", align, "justify", justify, "spacing", spacing, NULL) ||
+      (fontfile != NULL && *fontfile != '\0' &&
+        vips_object_set(VIPS_OBJECT(op), "fontfile", fontfile, NULL)) ||
+      (width > 0 && vips_object_set(VIPS_OBJECT(op), "width", width, NULL)) ||
+      vips_cache_operation_buildp(&op)) {
+    vips_object_unref_outputs(VIPS_OBJECT(op));
+    g_object_unref(op);
+    clear_image(&base);
+    return 1;
+  }
+  g_object_get(op, "out", &t[0], NULL);
+  vips_object_unref_outputs(VIPS_OBJECT(op));
+  g_object_unref(op);
+
+  // transparent padding for stroke and shadow
+  if (vips_embed(t[0], out, pad, pad, t[0]->Xsize + pad * 2, t[0]->Ysize + pad * 2, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+static int color_alpha_image(VipsImage *alpha, VipsImage **out, double r, double g, double b) {
+  double ones[3] = {1, 1, 1};
+  double color[3] = {r, g, b};
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 4);
+
+  // solid color with alpha band
+  if (vips_black(&t[0], alpha->Xsize, alpha->Ysize, "bands", 3, NULL) ||
+      vips_linear(t[0], &t[1], ones, color, 3, NULL) ||
+      vips_cast(t[1], &t[2], VIPS_FORMAT_UCHAR, NULL) ||
+      vips_bandjoin2(t[2], alpha, &t[3], NULL) ||
+      vips_copy(t[3], out, "interpretation", VIPS_INTERPRETATION_sRGB, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int stroke_image(VipsImage *in, VipsImage **out, int radius, double r, double g, double b) {
+  int size = radius * 2 + 1;
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 7);
+
+  // disc structuring element, 128 as don't care outside the disc
+  t[0] = vips_image_new_matrix(size, size);
+  for (int y = 0; y < size; y++) {
+    for (int x = 0; x < size; x++) {
+      int dx = x - radius, dy = y - radius;
+      *VIPS_MATRIX(t[0], x, y) = dx * dx + dy * dy <= radius * radius + radius ? 255 : 128;
+    }
+  }
+
+  // dilate thresholded alpha by the disc as outline beneath the image,
+  // with slight blur to antialias the outline edge
+  if (vips_extract_band(in, &t[1], in->Bands - 1, NULL) ||
+      vips_moreeq_const1(t[1], &t[2], 128, NULL) ||
+      vips_morph(t[2], &t[3], t[0], VIPS_OPERATION_MORPHOLOGY_DILATE, NULL) ||
+      vips_gaussblur(t[3], &t[4], 0.5, NULL) ||
+      color_alpha_image(t[4], &t[5], r, g, b) ||
+      vips_composite2(t[5], in, &t[6], VIPS_BLEND_MODE_OVER, NULL) ||
+      vips_cast(t[6], out, VIPS_FORMAT_UCHAR, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int drop_shadow_image(VipsImage *in, VipsImage **out, int x, int y, double sigma,
+                      double r, double g, double b, double opacity) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 7);
+  VipsImage *alpha;
+
+  // blurred alpha offset beneath the image
+  if (vips_extract_band(in, &t[0], in->Bands - 1, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  alpha = t[0];
+  if (sigma >= 0.5) {
+    if (vips_gaussblur(alpha, &t[1], sigma, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+    alpha = t[1];
+  }
+  if (vips_linear1(alpha, &t[2], opacity, 0, NULL) ||
+      vips_cast(t[2], &t[3], VIPS_FORMAT_UCHAR, NULL) ||
+      color_alpha_image(t[3], &t[4], r, g, b) ||
+      vips_embed(t[4], &t[5], x, y, in->Xsize, in->Ysize, NULL) ||
+      vips_composite2(t[5], in, &t[6], VIPS_BLEND_MODE_OVER, NULL) ||
+      vips_cast(t[6], out, VIPS_FORMAT_UCHAR, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
 int is_16bit(VipsInterpretation interpretation) {
   return interpretation == VIPS_INTERPRETATION_RGB16 ||
          interpretation == VIPS_INTERPRETATION_GREY16;
@@ -432,6 +780,500 @@ int to_colorspace(VipsImage *in, VipsImage **out, VipsInterpretation space) {
   return vips_colourspace(in, out, space, NULL);
 }
 
+static int pixelate_block(VipsImage *in, VipsImage **out, int size) {
+  VipsObject *base = VIPS_OBJECT(vips_image_new());
+  VipsImage **t = (VipsImage **) vips_object_local_array(base, 2);
+
+  // average blocks of size then scale back with nearest neighbour
+  if (
+    vips_shrink(in, &t[0], size, size, "ceil", TRUE, NULL) ||
+    vips_zoom(t[0], &t[1], size, size, NULL) ||
+    vips_extract_area(t[1], out, 0, 0, in->Xsize, in->Ysize, NULL)
+  ) {
+    g_object_unref(base);
+    return -1;
+  }
+  g_object_unref(base);
+  return 0;
+}
+
+int pixelate_image(VipsImage *in, VipsImage **out, int size) {
+  VipsObject *base = VIPS_OBJECT(vips_image_new());
+  int page_height = vips_image_get_page_height(in);
+  int in_width = in->Xsize;
+  int n_pages = in->Ysize / page_height;
+
+  VipsImage **page = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **pixelated = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **copy = (VipsImage **) vips_object_local_array(base, 1);
+
+  // pixelate each frame so that blocks do not cross frames
+  for (int i = 0; i < n_pages; i++) {
+    if (
+      vips_extract_area(in, &page[i], 0, page_height * i, in_width, page_height, NULL) ||
+      pixelate_block(page[i], &pixelated[i], size)
+    ) {
+      g_object_unref(base);
+      return -1;
+    }
+  }
+  if(
+    vips_arrayjoin(pixelated, &copy[0], n_pages, "across", 1, NULL) ||
+    vips_copy(copy[0], out, NULL)
+  ) {
+    g_object_unref(base);
+    return -1;
+  }
+  vips_image_set_int(*out, VIPS_META_PAGE_HEIGHT, page_height);
+  g_object_unref(base);
+  return 0;
+}
+
+int redact_image(VipsImage *in, VipsImage **out, int left, int top, int width, int height,
+                 int mode, double param, double r, double g, double b) {
+  VipsObject *base = VIPS_OBJECT(vips_image_new());
+  int page_height = vips_image_get_page_height(in);
+  int n_pages = in->Ysize / page_height;
+  int has_alpha = vips_image_hasalpha(in);
+  double scale = vips_interpretation_max_alpha(in->Type) / 255.0;
+  double a[in->Bands], c[in->Bands];
+
+  VipsImage **region = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **filled = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **redacted = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage **inserted = (VipsImage **) vips_object_local_array(base, n_pages);
+  VipsImage *tmp = in;
+
+  for (int i = 0; i < in->Bands; i++) {
+    a[i] = 0;
+    if (has_alpha && i == in->Bands - 1) {
+      c[i] = 255 * scale;
+    } else if (in->Bands < 3) {
+      c[i] = (r + g + b) / 3 * scale;
+    } else {
+      c[i] = (i == 0 ? r : i == 1 ? g : i == 2 ? b : 0) * scale;
+    }
+  }
+
+  // redact region of each frame:
+  // 0 pixelate by block size of param,
+  // 1 gaussian blur by sigma of param,
+  // 2 fill color
+  for (int i = 0; i < n_pages; i++) {
+    int y = top + page_height * i;
+    int err = vips_extract_area(in, &region[i], left, y, width, height, NULL);
+    if (!err) {
+      switch (mode) {
+        case 0:
+          err = pixelate_block(region[i], &redacted[i], (int) param);
+          break;
+        case 1:
+          err = vips_gaussblur(region[i], &redacted[i], param, NULL);
+          break;
+        default:
+          err = vips_linear(region[i], &filled[i], a, c, in->Bands, NULL) ||
+            vips_cast(filled[i], &redacted[i], in->BandFmt, NULL);
+      }
+    }
+    if (err || vips_insert(tmp, redacted[i], &inserted[i], left, y, NULL)) {
+      g_object_unref(base);
+      return -1;
+    }
+    tmp = inserted[i];
+  }
+  if (vips_copy(tmp, out, NULL)) {
+    g_object_unref(base);
+    return -1;
+  }
+  g_object_unref(base);
+  return 0;
+}
+
+int mask_image(VipsImage *in, VipsImage *mask, VipsImage **out, int invert) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 12);
+  int page_height = vips_image_get_page_height(in);
+  int n_pages = in->Ysize / page_height;
+  double max = max_alpha(in);
+  VipsImage *m;
+
+  // mask alpha if available, luminance otherwise
+  if (vips_image_hasalpha(mask)) {
+    if (vips_extract_band(mask, &t[0], mask->Bands - 1, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+  } else if (vips_colourspace(mask, &t[1], VIPS_INTERPRETATION_B_W, NULL) ||
+             vips_extract_band(t[1], &t[0], 0, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  // centered within each frame, transparent outside
+  if (vips_cast(t[0], &t[2], VIPS_FORMAT_UCHAR, NULL) ||
+      vips_embed(t[2], &t[3], (in->Xsize - mask->Xsize) / 2, (page_height - mask->Ysize) / 2,
+                 in->Xsize, page_height, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  m = t[3];
+  if (invert) {
+    if (vips_invert(m, &t[4], NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+    m = t[4];
+  }
+  if (n_pages > 1) {
+    if (vips_replicate(m, &t[5], 1, n_pages, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+    m = t[5];
+  }
+  if (vips_image_hasalpha(in)) {
+    // multiply existing alpha by mask
+    if (vips_extract_band(in, &t[6], 0, "n", in->Bands - 1, NULL) ||
+        vips_extract_band(in, &t[7], in->Bands - 1, NULL) ||
+        vips_multiply(t[7], m, &t[8], NULL) ||
+        vips_linear1(t[8], &t[9], 1.0 / 255, 0, NULL) ||
+        vips_cast(t[9], &t[10], in->BandFmt, NULL) ||
+        vips_bandjoin2(t[6], t[10], &t[11], NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+  } else if (vips_linear1(m, &t[9], max / 255, 0, NULL) ||
+             vips_cast(t[9], &t[10], in->BandFmt, NULL) ||
+             vips_bandjoin2(in, t[10], &t[11], NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  if (vips_copy(t[11], out, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+// split_alpha extracts color bands, cast to uchar or ushort for histogram and LUT operations,
+// and alpha band if available
+static int split_alpha(VipsImage *base, VipsImage *in, VipsImage **color, VipsImage **alpha, int is16) {
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 2);
+  VipsBandFormat format = is16 ? VIPS_FORMAT_USHORT : VIPS_FORMAT_UCHAR;
+
+  *alpha = NULL;
+  if (vips_image_hasalpha(in)) {
+    if (vips_extract_band(in, &t[0], 0, "n", in->Bands - 1, NULL) ||
+        vips_extract_band(in, alpha, in->Bands - 1, NULL)) {
+      return 1;
+    }
+    vips_object_local(base, *alpha);
+  } else if (vips_copy(in, &t[0], NULL)) {
+    return 1;
+  }
+  if (t[0]->BandFmt == format) {
+    *color = t[0];
+    return 0;
+  }
+  if (vips_cast(t[0], &t[1], format, NULL)) {
+    return 1;
+  }
+  *color = t[1];
+  return 0;
+}
+
+static int join_alpha(VipsImage *color, VipsImage *alpha, VipsImage **out) {
+  if (alpha == NULL) {
+    return vips_copy(color, out, NULL);
+  }
+  return vips_bandjoin2(color, alpha, out, NULL);
+}
+
+// luminance_image single band luminance of color bands, in the same format
+static int luminance_image(VipsImage *base, VipsImage *color, VipsImage **out) {
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 2);
+
+  if (color->Bands < 3) {
+    return vips_extract_band(color, out, 0, NULL);
+  }
+  if (vips_colourspace(color, &t[0],
+        color->BandFmt == VIPS_FORMAT_USHORT ? VIPS_INTERPRETATION_GREY16 : VIPS_INTERPRETATION_B_W,
+        NULL) ||
+      vips_cast(t[0], &t[1], color->BandFmt, NULL)) {
+    return 1;
+  }
+  return vips_extract_band(t[1], out, 0, NULL);
+}
+
+int gamma_image(VipsImage *in, VipsImage **out, double exponent) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 1);
+  VipsImage *color, *alpha;
+
+  if (split_alpha(base, in, &color, &alpha, is_16bit(in->Type)) ||
+      vips_gamma(color, &t[0], "exponent", exponent, NULL) ||
+      join_alpha(t[0], alpha, out)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int maplut_image(VipsImage *in, VipsImage **out, double *lut, int n) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 3);
+  VipsImage *color, *alpha;
+  int is16 = n > 256;
+
+  if (split_alpha(base, in, &color, &alpha, is16) ||
+      !(t[0] = vips_image_new_from_memory_copy(lut, n * sizeof(double), n, 1, 1, VIPS_FORMAT_DOUBLE)) ||
+      vips_cast(t[0], &t[1], is16 ? VIPS_FORMAT_USHORT : VIPS_FORMAT_UCHAR, NULL) ||
+      vips_maplut(color, &t[2], t[1], NULL) ||
+      join_alpha(t[2], alpha, out)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int equalize_image(VipsImage *in, VipsImage **out) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 6);
+  VipsImage *color, *alpha;
+
+  // cumulative histogram of luminance mapped to all color bands, preserving hue
+  if (split_alpha(base, in, &color, &alpha, is_16bit(in->Type)) ||
+      luminance_image(base, color, &t[0]) ||
+      vips_hist_find(t[0], &t[1], NULL) ||
+      vips_hist_cum(t[1], &t[2], NULL) ||
+      vips_hist_norm(t[2], &t[3], NULL) ||
+      vips_cast(t[3], &t[4], color->BandFmt, NULL) ||
+      vips_maplut(color, &t[5], t[4], NULL) ||
+      join_alpha(t[5], alpha, out)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int normalize_image(VipsImage *in, VipsImage **out, double clip) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 3);
+  VipsImage *color, *alpha;
+  int is16 = is_16bit(in->Type);
+  double max = is16 ? 65535.0 : 255.0;
+  int low, high;
+
+  // stretch luminance percentiles to the full range
+  if (split_alpha(base, in, &color, &alpha, is16) ||
+      luminance_image(base, color, &t[0]) ||
+      vips_percent(t[0], clip, &low) ||
+      vips_percent(t[0], 100 - clip, &high)) {
+    clear_image(&base);
+    return 1;
+  }
+  if (high <= low) {
+    if (join_alpha(color, alpha, out)) {
+      clear_image(&base);
+      return 1;
+    }
+    clear_image(&base);
+    return 0;
+  }
+  if (vips_linear1(color, &t[1], max / (high - low), -low * max / (high - low), NULL) ||
+      vips_cast(t[1], &t[2], color->BandFmt, NULL) ||
+      join_alpha(t[2], alpha, out)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int recomb_image(VipsImage *in, VipsImage **out, double *matrix) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 3);
+  VipsImage *color, *alpha;
+
+  if (split_alpha(base, in, &color, &alpha, is_16bit(in->Type)) ||
+      !(t[0] = vips_image_new_matrix_from_array(3, 3, matrix, 9)) ||
+      vips_recomb(color, &t[1], t[0], NULL) ||
+      vips_cast(t[1], &t[2], color->BandFmt, NULL) ||
+      join_alpha(t[2], alpha, out)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int duotone_image(VipsImage *in, VipsImage **out, double *shadow, double *highlight) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 4);
+  VipsImage *color, *alpha;
+  int is16 = is_16bit(in->Type);
+  double scale = is16 ? 257.0 : 1.0;
+  double max = is16 ? 65535.0 : 255.0;
+  double a[3], b[3];
+  int i;
+
+  for (i = 0; i < 3; i++) {
+    a[i] = (highlight[i] - shadow[i]) * scale / max;
+    b[i] = shadow[i] * scale;
+  }
+  // luminance mapped between shadow and highlight colors
+  if (split_alpha(base, in, &color, &alpha, is16) ||
+      luminance_image(base, color, &t[0]) ||
+      vips_linear(t[0], &t[1], a, b, 3, NULL) ||
+      vips_cast(t[1], &t[2], color->BandFmt, NULL) ||
+      vips_copy(t[2], &t[3], "interpretation",
+                is16 ? VIPS_INTERPRETATION_RGB16 : VIPS_INTERPRETATION_sRGB, NULL) ||
+      join_alpha(t[3], alpha, out)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+// flood_corners_mask marks pixels of value 0 connected to the frame corners as 255, others as 0,
+// frame by frame
+static int flood_corners_mask(VipsImage *in, VipsImage **out) {
+  VipsImage *base = vips_image_new();
+  int page_height = vips_image_get_page_height(in);
+  int n_pages = in->Ysize / page_height;
+  VipsImage **pages = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), n_pages);
+  VipsImage **copies = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), n_pages);
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 2);
+  double ink[1] = {128};
+  int i, j;
+
+  for (i = 0; i < n_pages; i++) {
+    int corners[4][2] = {
+      {0, 0}, {in->Xsize - 1, 0}, {0, page_height - 1}, {in->Xsize - 1, page_height - 1},
+    };
+    if (vips_crop(in, &pages[i], 0, page_height * i, in->Xsize, page_height, NULL) ||
+        !(copies[i] = vips_image_copy_memory(pages[i]))) {
+      clear_image(&base);
+      return 1;
+    }
+    for (j = 0; j < 4; j++) {
+      double *point;
+      int n;
+      if (vips_getpoint(copies[i], &point, &n, corners[j][0], corners[j][1], NULL)) {
+        clear_image(&base);
+        return 1;
+      }
+      if (point[0] == 0 &&
+          vips_draw_flood(copies[i], ink, 1, corners[j][0], corners[j][1], "equal", TRUE, NULL)) {
+        g_free(point);
+        clear_image(&base);
+        return 1;
+      }
+      g_free(point);
+    }
+  }
+  if (vips_arrayjoin(copies, &t[0], n_pages, "across", 1, NULL) ||
+      vips_equal_const1(t[0], &t[1], 128, NULL) ||
+      vips_copy(t[1], out, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int transparent_image(VipsImage *in, VipsImage **out, double r, double g, double b,
+                      double tolerance, double feather, int flood) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 16);
+  VipsImage *color, *alpha, *mask;
+  double ones[3] = {1, 1, 1};
+  double key[3] = {-r, -g, -b};
+
+  // euclidean distance to the key color
+  if (split_alpha(base, in, &color, &alpha, 0) ||
+      vips_linear(color, &t[0], ones, key, 3, NULL) ||
+      vips_multiply(t[0], t[0], &t[1], NULL) ||
+      vips_bandmean(t[1], &t[2], NULL) ||
+      vips_linear1(t[2], &t[3], 3, 0, NULL) ||
+      vips_pow_const1(t[3], &t[4], 0.5, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  // transparent within tolerance, opaque beyond feather
+  if (feather > 0) {
+    if (vips_linear1(t[4], &t[5], 255.0 / feather, -tolerance * 255.0 / feather, NULL) ||
+        vips_cast(t[5], &t[6], VIPS_FORMAT_UCHAR, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+  } else if (vips_more_const1(t[4], &t[6], tolerance, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  mask = t[6];
+  if (flood) {
+    // only the key color regions connected to the corners
+    if (vips_more_const1(mask, &t[7], 254, NULL) ||
+        flood_corners_mask(t[7], &t[8]) ||
+        vips_invert(t[8], &t[9], NULL) ||
+        vips_ifthenelse(t[8], mask, t[9], &t[10], NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+    mask = t[10];
+  }
+  if (alpha != NULL) {
+    if (vips_multiply(alpha, mask, &t[11], NULL) ||
+        vips_linear1(t[11], &t[12], 1.0 / 255, 0, NULL) ||
+        vips_cast(t[12], &t[13], VIPS_FORMAT_UCHAR, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+    mask = t[13];
+  }
+  if (vips_bandjoin2(color, mask, &t[14], NULL) ||
+      vips_copy(t[14], out, "interpretation", VIPS_INTERPRETATION_sRGB, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
+int convolution_image(VipsImage *in, VipsImage **out, double *matrix, int columns, int rows, int normalize) {
+  VipsImage *base = vips_image_new();
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 4);
+  VipsImage *color, *alpha;
+  double scale = 0;
+  int i;
+
+  if (!(t[0] = vips_image_new_matrix_from_array(columns, rows, matrix, columns * rows))) {
+    clear_image(&base);
+    return 1;
+  }
+  if (normalize) {
+    for (i = 0; i < columns * rows; i++) {
+      scale += matrix[i];
+    }
+  }
+  vips_image_set_double(t[0], "scale", scale != 0 ? scale : 1);
+  if (split_alpha(base, in, &color, &alpha, is_16bit(in->Type)) ||
+      vips_conv(color, &t[1], t[0], "precision", VIPS_PRECISION_FLOAT, NULL) ||
+      vips_cast(t[1], &t[2], color->BandFmt, NULL) ||
+      join_alpha(t[2], alpha, &t[3]) ||
+      vips_copy(t[3], out, "interpretation", in->Type, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  clear_image(&base);
+  return 0;
+}
+
 int gaussian_blur_image(VipsImage *in, VipsImage **out, double sigma) {
   return vips_gaussblur(in, out, sigma, NULL);
 }
@@ -515,3 +1357,46 @@ int remove_exif(VipsImage *in, VipsImage **out) {
   g_strfreev(fields);
   return 0;
 }
+
+int ssim_tiles(VipsImage *in, void **buf, size_t *len, int *left, int *top, int n,
+  int tile_width, int tile_height) {
+  VipsImage *base = vips_image_new();
+  VipsImage **tile = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), n);
+  VipsImage **t = (VipsImage **) vips_object_local_array(VIPS_OBJECT(base), 4);
+  VipsImage *tmp;
+
+  // full resolution tiles of the first page joined in a row
+  for (int i = 0; i < n; i++) {
+    if (vips_extract_area(in, &tile[i], left[i], top[i], tile_width, tile_height, NULL)) {
+      clear_image(&base);
+      return 1;
+    }
+  }
+  if (vips_arrayjoin(tile, &t[0], n, "across", n, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  tmp = t[0];
+
+  if (vips_image_hasalpha(tmp)) {
+    if (flatten_image(tmp, &t[1], 255.0, 255.0, 255.0)) {
+      clear_image(&base);
+      return 1;
+    }
+    tmp = t[1];
+  }
+
+  if (vips_colourspace(tmp, &t[2], VIPS_INTERPRETATION_sRGB, NULL) ||
+      vips_cast(t[2], &t[3], VIPS_FORMAT_UCHAR, NULL)) {
+    clear_image(&base);
+    return 1;
+  }
+  tmp = t[3];
+
+  *buf = vips_image_write_to_memory(tmp, len);
+  clear_image(&base);
+  if (*buf == NULL) {
+    return 1;
+  }
+  return 0;
+}
@@ -104,6 +104,38 @@ func vipsThumbnailFromSource(
 	return out, imageType, nil
 }
 
+// https://www.libvips.org/API/current/libvips-resample.html#vips-thumbnail-buffer
+func vipsThumbnailFromBuffer(
+	buf []byte, width, height int, crop Interesting, size Size, params *ImportParams) (*C.VipsImage, ImageType, error) {
+	src := buf
+	// Reference src here so it's not garbage collected during image initialization.
+	defer runtime.KeepAlive(src)
+
+	var out *C.VipsImage
+	var code C.int
+	var optionString string
+
+	if params != nil {
+		optionString = params.OptionString()
+	}
+	if optionString == "" {
+		code = C.thumbnail_buffer(unsafe.Pointer(&src[0]), C.size_t(len(src)), &out,
+			C.int(width), C.int(height), C.int(crop), C.int(size))
+	} else {
+		cOptionString := C.CString(optionString)
+		defer freeCString(cOptionString)
+
+		code = C.thumbnail_buffer_with_option(unsafe.Pointer(&src[0]), C.size_t(len(src)), &out,
+			C.int(width), C.int(height), C.int(crop), C.int(size), cOptionString)
+	}
+	if code != 0 {
+		return nil, ImageTypeUnknown, handleImageError(out)
+	}
+
+	imageType := vipsDetermineImageTypeFromMetaLoader(out)
+	return out, imageType, nil
+}
+
 func clearImage(ref *C.VipsImage) {
 	C.clear_image(&ref)
 }
@@ -133,6 +165,17 @@ func vipsThumbnail(in *C.VipsImage, width, height int, crop Interesting, size Si
 	return out, nil
 }
 
+// https://libvips.github.io/libvips/API/current/libvips-resample.html#vips-resize
+func vipsResize(in *C.VipsImage, width, height int, crop Interesting, size Size, kernel Kernel) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.resize_image(in, &out, C.int(width), C.int(height), C.int(crop), C.int(size), C.int(kernel)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
 // https://libvips.github.io/libvips/API/current/libvips-conversion.html#vips-embed
 func vipsEmbed(in *C.VipsImage, left, top, width, height int, extend ExtendStrategy) (*C.VipsImage, error) {
 	var out *C.VipsImage
@@ -233,6 +276,175 @@ func vipsRotateMultiPage(in *C.VipsImage, angle Angle) (*C.VipsImage, error) {
 	return out, nil
 }
 
+func vipsBlurBackgroundMultiPage(in *C.VipsImage, width, height int, sigma, brightness float64) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.blur_background_multi_page(
+		in, &out, C.int(width), C.int(height), C.double(sigma), C.double(brightness),
+	); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://www.libvips.org/API/current/libvips-resample.html#vips-rotate
+func vipsRotateAngleMultiPage(in *C.VipsImage, angle float64, backgroundColor *ColorRGBA) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.rotate_angle_multi_page(in, &out, C.double(angle),
+		C.double(backgroundColor.R), C.double(backgroundColor.G),
+		C.double(backgroundColor.B), C.double(backgroundColor.A)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://www.libvips.org/API/current/libvips-resample.html#vips-affine
+func vipsAffineMultiPage(in *C.VipsImage, a, b, c, d float64, backgroundColor *ColorRGBA) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.affine_multi_page(in, &out, C.double(a), C.double(b), C.double(c), C.double(d),
+		C.double(backgroundColor.R), C.double(backgroundColor.G),
+		C.double(backgroundColor.B), C.double(backgroundColor.A)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://www.libvips.org/API/current/libvips-resample.html#vips-mapim
+func vipsPerspectiveMultiPage(in *C.VipsImage, matrix [9]float64, width, height int, backgroundColor *ColorRGBA) (*C.VipsImage, error) {
+	var out *C.VipsImage
+	cMatrix := make([]C.double, len(matrix))
+	for i, v := range matrix {
+		cMatrix[i] = C.double(v)
+	}
+
+	if err := C.perspective_multi_page(in, &out, &cMatrix[0], C.int(width), C.int(height),
+		C.double(backgroundColor.R), C.double(backgroundColor.G),
+		C.double(backgroundColor.B), C.double(backgroundColor.A)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+func vipsPixelate(in *C.VipsImage, size int) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.pixelate_image(in, &out, C.int(size)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+func vipsRedact(
+	in *C.VipsImage, left, top, width, height int, mode RedactMode, param float64, color *Color,
+) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.redact_image(in, &out, C.int(left), C.int(top), C.int(width), C.int(height),
+		C.int(mode), C.double(param), C.double(color.R), C.double(color.G), C.double(color.B)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+func vipsMask(in, mask *C.VipsImage, invert bool) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.mask_image(in, mask, &out, C.int(boolToInt(invert))); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://libvips.github.io/libvips/API/current/libvips-conversion.html#vips-gamma
+func vipsGamma(in *C.VipsImage, exponent float64) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.gamma_image(in, &out, C.double(exponent)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://libvips.github.io/libvips/API/current/libvips-histogram.html#vips-maplut
+func vipsMapLUT(in *C.VipsImage, lut []float64) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.maplut_image(in, &out, (*C.double)(&lut[0]), C.int(len(lut))); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://libvips.github.io/libvips/API/current/libvips-histogram.html#vips-hist-equal
+func vipsEqualize(in *C.VipsImage) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.equalize_image(in, &out); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://libvips.github.io/libvips/API/current/libvips-arithmetic.html#vips-percent
+func vipsNormalize(in *C.VipsImage, clip float64) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.normalize_image(in, &out, C.double(clip)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://libvips.github.io/libvips/API/current/libvips-conversion.html#vips-recomb
+func vipsRecomb(in *C.VipsImage, matrix [9]float64) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.recomb_image(in, &out, (*C.double)(&matrix[0])); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+func vipsDuotone(in *C.VipsImage, shadow, highlight *Color) (*C.VipsImage, error) {
+	var out *C.VipsImage
+	s := [3]float64{float64(shadow.R), float64(shadow.G), float64(shadow.B)}
+	h := [3]float64{float64(highlight.R), float64(highlight.G), float64(highlight.B)}
+
+	if err := C.duotone_image(in, &out, (*C.double)(&s[0]), (*C.double)(&h[0])); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+// https://libvips.github.io/libvips/API/current/libvips-draw.html#vips-draw-flood
+func vipsTransparent(
+	in *C.VipsImage, color *Color, tolerance, feather float64, flood bool,
+) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.transparent_image(in, &out, C.double(color.R), C.double(color.G), C.double(color.B),
+		C.double(tolerance), C.double(feather), C.int(boolToInt(flood))); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
 // https://libvips.github.io/libvips/API/current/libvips-conversion.html#vips-flatten
 func vipsFlatten(in *C.VipsImage, color *Color) (*C.VipsImage, error) {
 	var out *C.VipsImage
@@ -267,6 +479,48 @@ func vipsLabel(
 	return out, nil
 }
 
+// https://www.libvips.org/API/current/libvips-create.html#vips-text
+func vipsText(
+	text, font, fontFile string, width int, align Align, justify bool, spacing, pad int,
+) (*C.VipsImage, error) {
+	var out *C.VipsImage
+	cText := C.CString(text)
+	defer freeCString(cText)
+	cFont := C.CString(font)
+	defer freeCString(cFont)
+	cFontFile := C.CString(fontFile)
+	defer freeCString(cFontFile)
+
+	if err := C.text_image(&out, cText, cFont, cFontFile, C.int(width), C.VipsAlign(align),
+		C.int(boolToInt(justify)), C.int(spacing), C.int(pad)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+func vipsStroke(in *C.VipsImage, radius int, color *Color) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.stroke_image(in, &out, C.int(radius),
+		C.double(color.R), C.double(color.G), C.double(color.B)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
+func vipsDropShadow(in *C.VipsImage, x, y int, sigma float64, color *Color, opacity float64) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.drop_shadow_image(in, &out, C.int(x), C.int(y), C.double(sigma),
+		C.double(color.R), C.double(color.G), C.double(color.B), C.double(opacity)); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
 func vipsAddAlpha(in *C.VipsImage) (*C.VipsImage, error) {
 	var out *C.VipsImage
 
@@ -347,6 +601,18 @@ func vipsToColorSpace(in *C.VipsImage, interpretation Interpretation) (*C.VipsIm
 	return out, nil
 }
 
+// https://libvips.github.io/libvips/API/current/libvips-convolution.html#vips-conv
+func vipsConvolution(in *C.VipsImage, matrix []float64, columns int, normalize bool) (*C.VipsImage, error) {
+	var out *C.VipsImage
+
+	if err := C.convolution_image(in, &out, (*C.double)(unsafe.Pointer(&matrix[0])),
+		C.int(columns), C.int(len(matrix)/columns), C.int(boolToInt(normalize))); err != 0 {
+		return nil, handleImageError(out)
+	}
+
+	return out, nil
+}
+
 // https://libvips.github.io/libvips/API/current/libvips-convolution.html#vips-gaussblur
 func vipsGaussianBlur(in *C.VipsImage, sigma float64) (*C.VipsImage, error) {
 	var out *C.VipsImage
@@ -416,3 +682,22 @@ func vipsImageSetDelay(in *C.VipsImage, data []C.int) error {
 func vipsGetMetaString(image *C.VipsImage, name string) string {
 	return C.GoString(C.get_meta_string(image, cachedCString(name)))
 }
+
+func vipsSSIMTiles(in *C.VipsImage, left, top []int, tileWidth, tileHeight int) ([]byte, error) {
+	var ptr unsafe.Pointer
+	var ln C.size_t
+	n := len(left)
+	cLeft := make([]C.int, n)
+	cTop := make([]C.int, n)
+	for i := 0; i < n; i++ {
+		cLeft[i], cTop[i] = C.int(left[i]), C.int(top[i])
+	}
+
+	if err := C.ssim_tiles(in, &ptr, &ln, &cLeft[0], &cTop[0], C.int(n),
+		C.int(tileWidth), C.int(tileHeight)); err != 0 {
+		return nil, handleVipsError()
+	}
+	defer gFreePointer(ptr)
+
+	return C.GoBytes(ptr, C.int(ln)), nil
+}
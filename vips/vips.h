@@ -25,6 +25,8 @@ int thumbnail(const char *filename, VipsImage **out, int width, int height,
                     int crop, int size);
 int thumbnail_image(VipsImage *in, VipsImage **out, int width, int height,
                     int crop, int size);
+int resize_image(VipsImage *in, VipsImage **out, int width, int height,
+                 int crop, int size, int kernel);
 int thumbnail_buffer(void *buf, size_t len, VipsImage **out, int width, int height,
                     int crop, int size);
 int thumbnail_buffer_with_option(void *buf, size_t len, VipsImage **out,
@@ -46,6 +48,13 @@ int embed_multi_page_image(VipsImage *in, VipsImage **out, int left, int top, in
 int embed_multi_page_image_background(VipsImage *in, VipsImage **out, int left, int top,
                 int width, int height, double r, double g, double b, double a);
 
+int rotate_angle_multi_page(VipsImage *in, VipsImage **out, double angle,
+                            double r, double g, double b, double a);
+int affine_multi_page(VipsImage *in, VipsImage **out, double m0, double m1, double m2, double m3,
+                      double r, double g, double b, double a);
+int perspective_multi_page(VipsImage *in, VipsImage **out, double *matrix, int width, int height,
+                           double r, double g, double b, double a);
+
 int flip_image(VipsImage *in, VipsImage **out, int direction);
 
 int extract_image_area(VipsImage *in, VipsImage **out, int left, int top,
@@ -53,6 +62,9 @@ int extract_image_area(VipsImage *in, VipsImage **out, int left, int top,
 int extract_area_multi_page(VipsImage *in, VipsImage **out, int left, int top,
                        int width, int height);
 
+int blur_background_multi_page(VipsImage *in, VipsImage **out, int width, int height,
+                               double sigma, double brightness);
+
 int rotate_image(VipsImage *in, VipsImage **out, VipsAngle angle);
 int rotate_image_multi_page(VipsImage *in, VipsImage **out, VipsAngle angle);
 int flatten_image(VipsImage *in, VipsImage **out, double r, double g, double b);
@@ -66,6 +78,12 @@ double max_alpha(VipsImage *in);
 int composite2_image(VipsImage *base, VipsImage *overlay, VipsImage **out,
                      int mode, gint x, gint y);
 
+int text_image(VipsImage **out, const char *text, const char *font, const char *fontfile,
+               int width, VipsAlign align, int justify, int spacing, int pad);
+int stroke_image(VipsImage *in, VipsImage **out, int radius, double r, double g, double b);
+int drop_shadow_image(VipsImage *in, VipsImage **out, int x, int y, double sigma,
+                      double r, double g, double b, double opacity);
+
 int is_16bit(VipsInterpretation interpretation);
 
 int replicate(VipsImage *in, VipsImage **out, int across, int down);
@@ -78,6 +96,20 @@ int getpoint(VipsImage *in, double **vector, int n, int x, int y);
 
 int to_colorspace(VipsImage *in, VipsImage **out, VipsInterpretation space);
 
+int pixelate_image(VipsImage *in, VipsImage **out, int size);
+int redact_image(VipsImage *in, VipsImage **out, int left, int top, int width, int height,
+                 int mode, double param, double r, double g, double b);
+int mask_image(VipsImage *in, VipsImage *mask, VipsImage **out, int invert);
+int gamma_image(VipsImage *in, VipsImage **out, double exponent);
+int maplut_image(VipsImage *in, VipsImage **out, double *lut, int n);
+int equalize_image(VipsImage *in, VipsImage **out);
+int normalize_image(VipsImage *in, VipsImage **out, double clip);
+int recomb_image(VipsImage *in, VipsImage **out, double *matrix);
+int duotone_image(VipsImage *in, VipsImage **out, double *shadow, double *highlight);
+int convolution_image(VipsImage *in, VipsImage **out, double *matrix, int columns, int rows, int normalize);
+int transparent_image(VipsImage *in, VipsImage **out, double r, double g, double b,
+                      double tolerance, double feather, int flood);
+
 int gaussian_blur_image(VipsImage *in, VipsImage **out, double sigma);
 int sharpen_image(VipsImage *in, VipsImage **out, double sigma, double x1,
                   double m2);
@@ -92,3 +124,6 @@ int get_meta_loader(const VipsImage *in, const char **out);
 void set_image_delay(VipsImage *in, const int *array, int n);
 const char * get_meta_string(const VipsImage *image, const char *name);
 int remove_exif(VipsImage *in, VipsImage **out);
+
+int ssim_tiles(VipsImage *in, void **buf, size_t *len, int *left, int *top, int n,
+  int tile_width, int tile_height);